@@ -0,0 +1,323 @@
+// Package diagram converts ascii-art diagrams to SVG. The boxes, lines, arrows and dots are drawn,
+// all other characters are kept as text. The SVG conforms to the SVG profile for RFCs (RFC 7996).
+package diagram
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+const (
+	cellWidth  = 8  // Width of a character in the SVG.
+	cellHeight = 16 // Height of a character in the SVG.
+	tabWidth   = 8
+)
+
+// The characters that may connect to a neighbour on their right, left, bottom or top side.
+const (
+	reachesRight = "-+*o.'<"
+	reachesLeft  = "-+*o.'>"
+	reachesDown  = "|+*o.^"
+	reachesUp    = "|+*o'v"
+)
+
+// SVG returns the ascii-art in text as an SVG image.
+func SVG(text []byte) []byte {
+	g := newGrid(text)
+	d := &drawing{}
+	for y := range g {
+		for x := range g[y] {
+			g.cell(d, x, y)
+		}
+	}
+	d.lines(g)
+	return d.svg(g)
+}
+
+// grid holds the ascii-art, one line of characters per row.
+type grid [][]rune
+
+func newGrid(text []byte) grid {
+	g := grid{}
+	for _, line := range strings.Split(strings.TrimRight(string(text), "\n"), "\n") {
+		row := []rune{}
+		for _, r := range line {
+			if r == '\t' {
+				for n := tabWidth - len(row)%tabWidth; n > 0; n-- {
+					row = append(row, ' ')
+				}
+				continue
+			}
+			row = append(row, r)
+		}
+		g = append(g, row)
+	}
+	return g
+}
+
+// at returns the character at x, y, outside of the grid this is a space.
+func (g grid) at(x, y int) rune {
+	if y < 0 || y >= len(g) || x < 0 || x >= len(g[y]) {
+		return ' '
+	}
+	return g[y][x]
+}
+
+func (g grid) width() int {
+	w := 0
+	for _, row := range g {
+		if len(row) > w {
+			w = len(row)
+		}
+	}
+	return w
+}
+
+// edges tells to which sides of its cell a drawing character connects.
+type edges struct {
+	left, right, up, down bool
+}
+
+// connects returns the edges of the cell at x, y that connect to its neighbours.
+func (g grid) connects(x, y int) edges {
+	return edges{
+		left:  strings.ContainsRune(reachesRight, g.at(x-1, y)),
+		right: strings.ContainsRune(reachesLeft, g.at(x+1, y)),
+		up:    strings.ContainsRune(reachesDown, g.at(x, y-1)),
+		down:  strings.ContainsRune(reachesUp, g.at(x, y+1)),
+	}
+}
+
+// drawing collects the SVG elements of a diagram.
+type drawing struct {
+	edges     map[[2]int]edges // Edges of the cells with line segments.
+	cells     map[[2]int]bool  // Cells that are drawn, the others are text.
+	paths     []string
+	circles   []string
+	polygons  []string
+	diagonals [][2]int // Cells with a '/' or '\'.
+}
+
+func (d *drawing) add(x, y int, e edges) {
+	if d.edges == nil {
+		d.edges = map[[2]int]edges{}
+	}
+	d.edges[[2]int{x, y}] = e
+	d.drawn(x, y)
+}
+
+func (d *drawing) drawn(x, y int) {
+	if d.cells == nil {
+		d.cells = map[[2]int]bool{}
+	}
+	d.cells[[2]int{x, y}] = true
+}
+
+// cell decides if the character at x, y is part of the drawing and adds it to d.
+func (g grid) cell(d *drawing, x, y int) {
+	e := g.connects(x, y)
+	cx, cy := x*cellWidth+cellWidth/2, y*cellHeight+cellHeight/2
+	switch r := g.at(x, y); r {
+	case '-':
+		if strings.ContainsRune("-+<>*.'|", g.at(x-1, y)) || strings.ContainsRune("-+<>*.'|", g.at(x+1, y)) {
+			d.add(x, y, edges{left: true, right: true})
+		}
+	case '|':
+		if strings.ContainsRune("|+.'^v*", g.at(x, y-1)) || strings.ContainsRune("|+.'^v*", g.at(x, y+1)) {
+			d.add(x, y, edges{up: true, down: true})
+		}
+	case '+':
+		if e.left || e.right || e.up || e.down {
+			d.add(x, y, e)
+		}
+	case '*', 'o':
+		if r == 'o' {
+			// Only a circle when it is on a line, not when it's part of a word.
+			e = edges{left: g.at(x-1, y) == '-', right: g.at(x+1, y) == '-', up: g.at(x, y-1) == '|', down: g.at(x, y+1) == '|'}
+		}
+		if !(e.left || e.right || e.up || e.down) {
+			return
+		}
+		d.add(x, y, e)
+		fill := "black"
+		if r == 'o' {
+			fill = "white"
+		}
+		d.circles = append(d.circles, fmt.Sprintf(`<circle cx="%d" cy="%d" r="3" fill="%s"/>`, cx, cy, fill))
+	case '.', '\'':
+		left, right := g.at(x-1, y) == '-', g.at(x+1, y) == '-'
+		vertical := (r == '.' && strings.ContainsRune("|+", g.at(x, y+1))) || (r == '\'' && strings.ContainsRune("|+", g.at(x, y-1)))
+		if !(left || right) || !vertical {
+			return
+		}
+		if left && right {
+			d.add(x, y, edges{left: true, right: true, up: r == '\'', down: r == '.'})
+			return
+		}
+		d.drawn(x, y)
+		hx := x * cellWidth
+		if right {
+			hx += cellWidth
+		}
+		vy := y * cellHeight
+		if r == '.' {
+			vy += cellHeight
+		}
+		d.paths = append(d.paths, fmt.Sprintf("M%d,%d Q%d,%d %d,%d", hx, cy, cx, cy, cx, vy))
+	case '>':
+		if e.left && g.at(x-1, y) != '>' {
+			d.add(x, y, edges{left: true})
+			d.arrow(x*cellWidth+cellWidth, cy, x*cellWidth, cy-4, x*cellWidth, cy+4)
+		}
+	case '<':
+		if e.right && g.at(x+1, y) != '<' {
+			d.add(x, y, edges{right: true})
+			d.arrow(x*cellWidth, cy, x*cellWidth+cellWidth, cy-4, x*cellWidth+cellWidth, cy+4)
+		}
+	case '^':
+		if strings.ContainsRune("|+", g.at(x, y+1)) {
+			d.add(x, y, edges{down: true})
+			d.arrow(cx, y*cellHeight, cx-4, cy, cx+4, cy)
+		}
+	case 'v':
+		if strings.ContainsRune("|+", g.at(x, y-1)) {
+			d.add(x, y, edges{up: true})
+			d.arrow(cx, y*cellHeight+cellHeight, cx-4, cy, cx+4, cy)
+		}
+	case '/':
+		if strings.ContainsRune("/+.'", g.at(x+1, y-1)) || strings.ContainsRune("/+.'", g.at(x-1, y+1)) {
+			d.drawn(x, y)
+			d.diagonals = append(d.diagonals, [2]int{x, y})
+		}
+	case '\\':
+		if strings.ContainsRune("\\+.'", g.at(x-1, y-1)) || strings.ContainsRune("\\+.'", g.at(x+1, y+1)) {
+			d.drawn(x, y)
+			d.diagonals = append(d.diagonals, [2]int{x, y})
+		}
+	}
+}
+
+// arrow adds an arrow head with its point at x0, y0.
+func (d *drawing) arrow(x0, y0, x1, y1, x2, y2 int) {
+	d.polygons = append(d.polygons, fmt.Sprintf(`<polygon points="%d,%d %d,%d %d,%d"/>`, x0, y0, x1, y1, x2, y2))
+}
+
+// lines turns the edges of the cells into horizontal and vertical lines and joins the diagonals,
+// each line is made as long as possible.
+func (d *drawing) lines(g grid) {
+	// Each cell is split in two halves, a line runs over consecutive halves.
+	run := func(halves []bool, line func(from, to int)) {
+		start := -1
+		for i := 0; i <= len(halves); i++ {
+			if i < len(halves) && halves[i] {
+				if start < 0 {
+					start = i
+				}
+				continue
+			}
+			if start >= 0 {
+				line(start, i)
+				start = -1
+			}
+		}
+	}
+
+	w := g.width()
+	for y := range g {
+		halves := make([]bool, 2*w)
+		for x := 0; x < w; x++ {
+			e := d.edges[[2]int{x, y}]
+			halves[2*x], halves[2*x+1] = e.left, e.right
+		}
+		cy := y*cellHeight + cellHeight/2
+		run(halves, func(from, to int) {
+			d.paths = append(d.paths, fmt.Sprintf("M%d,%d H%d", from*cellWidth/2, cy, to*cellWidth/2))
+		})
+	}
+	for x := 0; x < w; x++ {
+		halves := make([]bool, 2*len(g))
+		for y := range g {
+			e := d.edges[[2]int{x, y}]
+			halves[2*y], halves[2*y+1] = e.up, e.down
+		}
+		cx := x*cellWidth + cellWidth/2
+		run(halves, func(from, to int) {
+			d.paths = append(d.paths, fmt.Sprintf("M%d,%d V%d", cx, from*cellHeight/2, to*cellHeight/2))
+		})
+	}
+
+	diagonal := map[[2]int]bool{}
+	for _, c := range d.diagonals {
+		diagonal[c] = true
+	}
+	for _, c := range d.diagonals {
+		x, y := c[0], c[1]
+		// A '/' runs up and to the right, a '\' down and to the right. Only start at the beginning of one.
+		dy := -1
+		if g.at(x, y) == '\\' {
+			dy = 1
+		}
+		if diagonal[[2]int{x - 1, y - dy}] && g.at(x-1, y-dy) == g.at(x, y) {
+			continue
+		}
+		n := 1
+		for diagonal[[2]int{x + n, y + n*dy}] && g.at(x+n, y+n*dy) == g.at(x, y) {
+			n++
+		}
+		y0, y1 := y*cellHeight+cellHeight, (y+n*dy)*cellHeight+cellHeight
+		if dy > 0 {
+			y0, y1 = y*cellHeight, (y+n-1)*cellHeight+cellHeight
+		}
+		d.paths = append(d.paths, fmt.Sprintf("M%d,%d L%d,%d", x*cellWidth, y0, (x+n)*cellWidth, y1))
+	}
+}
+
+// svg returns the SVG of the drawing, the characters of g that are not drawn become text.
+func (d *drawing) svg(g grid) []byte {
+	w, h := g.width()*cellWidth, len(g)*cellHeight
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, `<svg xmlns="http://www.w3.org/2000/svg" version="1.2" baseProfile="tiny" viewBox="0 0 %d %d" width="%d" height="%d" font-family="monospace" font-size="13">`, w, h, w, h)
+	buf.WriteString("\n")
+	if len(d.paths) > 0 || len(d.circles) > 0 {
+		buf.WriteString(`<g stroke="black" stroke-width="2" stroke-linecap="round" fill="none">` + "\n")
+		if len(d.paths) > 0 {
+			buf.WriteString(`<path d="` + strings.Join(d.paths, " ") + `"/>` + "\n")
+		}
+		for _, c := range d.circles {
+			buf.WriteString(c + "\n")
+		}
+		buf.WriteString("</g>\n")
+	}
+	if len(d.polygons) > 0 {
+		buf.WriteString(`<g fill="black">` + "\n")
+		for _, p := range d.polygons {
+			buf.WriteString(p + "\n")
+		}
+		buf.WriteString("</g>\n")
+	}
+
+	// Text runs over words separated by a single space, as a space isn't collapsed then.
+	for y, row := range g {
+		for x := 0; x < len(row); x++ {
+			if !d.isText(g, x, y) {
+				continue
+			}
+			start := x
+			for x < len(row) && (d.isText(g, x, y) || (row[x] == ' ' && d.isText(g, x+1, y) && d.isText(g, x-1, y))) {
+				x++
+			}
+			fmt.Fprintf(buf, `<text x="%d" y="%d">`, start*cellWidth, y*cellHeight+cellHeight*3/4)
+			xml.EscapeText(buf, []byte(string(row[start:x])))
+			buf.WriteString("</text>\n")
+		}
+	}
+	buf.WriteString("</svg>")
+	return buf.Bytes()
+}
+
+// isText returns true if the character at x, y is text: not a space and not drawn.
+func (d *drawing) isText(g grid, x, y int) bool {
+	return g.at(x, y) != ' ' && !d.cells[[2]int{x, y}]
+}
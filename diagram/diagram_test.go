@@ -0,0 +1,39 @@
+package diagram
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mmarkdown/mmark/xml"
+)
+
+func TestSVG(t *testing.T) {
+	art := `.-----.     +-----+
+| A&B |---->|  B  |
+'-----'     +--+--+
+               |
+               v
+   /   o--*  well-known
+  /
+`
+	got := string(SVG([]byte(art)))
+	for _, want := range []string{
+		`viewBox="0 0 184 112"`,
+		`<text x="16" y="28">A&amp;B</text>`,
+		`<text x="120" y="28">B</text>`,
+		`<text x="104" y="92">well-known</text>`,
+		`M8,8 Q4,8 4,16`,                        // top-left rounded corner
+		`M56,24 H92`,                            // line to the arrow
+		`<polygon points="96,24 88,20 88,28"/>`, // arrow head
+		`M16,112 L32,80`,                        // diagonal
+		`<circle cx="60" cy="88" r="3" fill="white"/>`,
+		`<circle cx="84" cy="88" r="3" fill="black"/>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want %q in SVG, got %s", want, got)
+		}
+	}
+	if errs := xml.CheckSVG([]byte(got)); len(errs) != 0 {
+		t.Errorf("want SVG that conforms to RFC 7996, got %v", errs)
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/mast"
+	"github.com/mmarkdown/mmark/mparser"
+)
+
+// DraftExpire is the number of days an Internet-Draft is valid, see
+// https://www.ietf.org/standards/ids/guidelines/ (section 8).
+const DraftExpire = 185
+
+func draftUsage() {
+	fmt.Fprintf(os.Stderr, "SYNOPSIS: %s draft expire|bump|check FILE...\n", os.Args[0])
+	fmt.Fprintln(os.Stderr, "\nCOMMANDS:")
+	fmt.Fprintln(os.Stderr, "  expire\tshow the date the draft expires")
+	fmt.Fprintln(os.Stderr, "  bump\t\tincrement the version of the draft in place")
+	fmt.Fprintln(os.Stderr, "  check\t\tcheck that the file name matches the name of the draft")
+}
+
+// draftMain implements the "draft" subcommands, it returns the exit code for the program.
+func draftMain(args []string) int {
+	if len(args) < 2 {
+		draftUsage()
+		return 2
+	}
+
+	var cmd func(string, []byte, *mast.Title) error
+	switch args[0] {
+	case "expire":
+		cmd = draftExpire
+	case "bump":
+		cmd = draftBump
+	case "check":
+		cmd = draftCheck
+	default:
+		draftUsage()
+		return 2
+	}
+
+	code := 0
+	for _, fileName := range args[1:] {
+		d, err := ioutil.ReadFile(fileName)
+		if err != nil {
+			log.Printf("Couldn't open %q: %q", fileName, err)
+			code = 1
+			continue
+		}
+		t := draftTitle(fileName, d)
+		if t == nil || t.SeriesInfo.Name != "Internet-Draft" {
+			log.Printf("No Internet-Draft seriesInfo found in title block of %q", fileName)
+			code = 1
+			continue
+		}
+		if err := cmd(fileName, d, t); err != nil {
+			log.Printf("%s: %s", fileName, err)
+			code = 1
+		}
+	}
+	return code
+}
+
+// draftTitle parses data and returns the title block, or nil if there is none.
+func draftTitle(fileName string, data []byte) *mast.Title {
+	init := mparser.NewInitial(fileName)
+	p := parser.NewWithExtensions(Extensions)
+	p.Opts = parser.ParserOptions{
+		ParserHook:    mparser.TitleHook,
+		ReadIncludeFn: init.ReadInclude,
+	}
+	doc := markdown.Parse(data, p)
+
+	var t *mast.Title
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if x, ok := node.(*mast.Title); ok {
+			t = x
+			return ast.Terminate
+		}
+		return ast.GoToNext
+	})
+	return t
+}
+
+func draftExpire(fileName string, _ []byte, t *mast.Title) error {
+	date := t.Date
+	if date.IsZero() { // xml2rfc will use today's date
+		date = mast.NewDate(time.Now().UTC())
+	}
+	if date.Day == 0 {
+		return fmt.Errorf("date %q has no day, can't calculate expiry date", date)
+	}
+	expire := date.Time().AddDate(0, 0, DraftExpire)
+	fmt.Printf("%s expires %s\n", t.SeriesInfo.Value, expire.Format("2006-01-02"))
+	return nil
+}
+
+func draftBump(fileName string, data []byte, t *mast.Title) error {
+	next, err := DraftBump(t.SeriesInfo.Value)
+	if err != nil {
+		return err
+	}
+	data, err = replaceInTitleBlock(data, t.SeriesInfo.Value, next)
+	if err != nil {
+		return err
+	}
+	fi, err := os.Stat(fileName)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(fileName, data, fi.Mode()); err != nil {
+		return err
+	}
+	fmt.Printf("%s -> %s\n", t.SeriesInfo.Value, next)
+	return nil
+}
+
+func draftCheck(fileName string, _ []byte, t *mast.Title) error {
+	base := filepath.Base(fileName)
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	if base != t.SeriesInfo.Value {
+		return fmt.Errorf("file name %q does not match draft name %q", base, t.SeriesInfo.Value)
+	}
+	return nil
+}
+
+var draftVersion = regexp.MustCompile(`-(\d{2})$`)
+
+// DraftBump returns the name of the next version of the Internet-Draft name, i.e.
+// draft-gieben-mmark-00 becomes draft-gieben-mmark-01.
+func DraftBump(name string) (string, error) {
+	m := draftVersion.FindStringSubmatchIndex(name)
+	if m == nil {
+		return "", fmt.Errorf("draft name %q does not end in a -NN version", name)
+	}
+	version, _ := strconv.Atoi(name[m[2]:m[3]])
+	if version >= 99 {
+		return "", fmt.Errorf("draft name %q can't be bumped past version 99", name)
+	}
+	return fmt.Sprintf("%s%02d", name[:m[2]], version+1), nil
+}
+
+// replaceInTitleBlock replaces the first occurrence of old in the title block of data with new.
+func replaceInTitleBlock(data []byte, old, new string) ([]byte, error) {
+	start := bytes.Index(data, []byte("%%%"))
+	if start < 0 {
+		return nil, errors.New("no title block found")
+	}
+	end := bytes.Index(data[start+3:], []byte("%%%"))
+	if end < 0 {
+		return nil, errors.New("no end of title block found")
+	}
+	end += start + 3
+
+	i := bytes.Index(data[start:end], []byte(old))
+	if i < 0 {
+		return nil, fmt.Errorf("draft name %q not found in title block", old)
+	}
+	i += start
+
+	out := make([]byte, 0, len(data)+len(new)-len(old))
+	out = append(out, data[:i]...)
+	out = append(out, new...)
+	out = append(out, data[i+len(old):]...)
+	return out, nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import "testing"
+
+func TestDraftBump(t *testing.T) {
+	tests := map[string]string{
+		"draft-gieben-mmark2rfc-00": "draft-gieben-mmark2rfc-01",
+		"draft-ietf-dnsop-foo-09":   "draft-ietf-dnsop-foo-10",
+	}
+	for in, want := range tests {
+		got, err := DraftBump(in)
+		if err != nil {
+			t.Errorf("failed to bump %q: %s", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("want %s, got %s, for input %s", want, got, in)
+		}
+	}
+
+	for _, in := range []string{"draft-gieben-mmark2rfc", "draft-ietf-dnsop-foo-99", "draft-foo-1"} {
+		if _, err := DraftBump(in); err == nil {
+			t.Errorf("expected error for input %s", in)
+		}
+	}
+}
+
+func TestReplaceInTitleBlock(t *testing.T) {
+	data := []byte(`%%%
+value = "draft-foo-00"
+%%%
+
+See draft-foo-00.
+`)
+	want := `%%%
+value = "draft-foo-01"
+%%%
+
+See draft-foo-00.
+`
+	got, err := replaceInTitleBlock(data, "draft-foo-00", "draft-foo-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+}
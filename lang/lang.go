@@ -0,0 +1,275 @@
+// Package lang holds the labels mmark generates in the output, like "Footnotes" or "Table of
+// Contents", for a number of languages. The labels for a language can be overridden from a TOML file.
+package lang
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Lang holds the generated labels in a single language.
+type Lang struct {
+	Tag string `toml:"-"` // Language tag, i.e. "en".
+
+	// Sections.
+	Footnotes             string
+	Index                 string
+	TableOfContents       string
+	Contents              string // Link to the table of contents in the navigation of a book.
+	Start                 string // Title of the first page of a book, when the document has no title.
+	NormativeReferences   string
+	InformativeReferences string
+	AuthorsAddresses      string
+	AuthorsAddress        string // Used when there is only one author.
+
+	// Cross references and captions.
+	Section  string
+	Appendix string
+	Figure   string
+	Table    string
+	Of       string // Between a section and the document it is in, i.e. "Section 2 of [RFC2119]".
+
+	// Title page.
+	Workgroup      string
+	Updates        string
+	Obsoletes      string
+	Published      string
+	Category       string
+	IntendedStatus string
+	Authors        string
+	Phone          string
+	Email          string
+	URI            string
+
+	// Document statuses on the title page, see Status.
+	StandardsTrack      string
+	Informational       string
+	Experimental        string
+	BestCurrentPractice string
+	FYI                 string
+
+	// Bibliography entries.
+	And    string
+	EtAl   string
+	Months []string
+
+	IndexReturn string // Text of the link from an index entry back to the text.
+	Search      string // Placeholder of the search box.
+}
+
+// Languages holds the labels for each language tag.
+var Languages = map[string]Lang{
+	"en": {
+		Footnotes:             "Footnotes",
+		Index:                 "Index",
+		TableOfContents:       "Table of Contents",
+		Contents:              "Contents",
+		Start:                 "Start",
+		NormativeReferences:   "Normative References",
+		InformativeReferences: "Informative References",
+		AuthorsAddresses:      "Authors' Addresses",
+		AuthorsAddress:        "Author's Address",
+		Section:               "Section",
+		Appendix:              "Appendix",
+		Figure:                "Figure",
+		Table:                 "Table",
+		Of:                    "of",
+		Workgroup:             "Workgroup",
+		Updates:               "Updates",
+		Obsoletes:             "Obsoletes",
+		Published:             "Published",
+		Category:              "Category",
+		IntendedStatus:        "Intended Status",
+		Authors:               "Authors",
+		Phone:                 "Phone",
+		Email:                 "Email",
+		URI:                   "URI",
+		StandardsTrack:        "Standards Track",
+		Informational:         "Informational",
+		Experimental:          "Experimental",
+		BestCurrentPractice:   "Best Current Practice",
+		FYI:                   "FYI",
+		And:                   "and",
+		EtAl:                  "et al.",
+		Months: []string{"January", "February", "March", "April", "May", "June", "July", "August",
+			"September", "October", "November", "December"},
+		IndexReturn: "go",
+		Search:      "Search",
+	},
+	"nl": {
+		Footnotes:             "Voetnoten",
+		Index:                 "Index",
+		TableOfContents:       "Inhoudsopgave",
+		Contents:              "Inhoud",
+		Start:                 "Start",
+		NormativeReferences:   "Normatieve Referenties",
+		InformativeReferences: "Informatieve Referenties",
+		AuthorsAddresses:      "Adressen van de Auteurs",
+		AuthorsAddress:        "Adres van de Auteur",
+		Section:               "Sectie",
+		Appendix:              "Bijlage",
+		Figure:                "Figuur",
+		Table:                 "Tabel",
+		Of:                    "van",
+		Workgroup:             "Werkgroep",
+		Updates:               "Wijzigt",
+		Obsoletes:             "Vervangt",
+		Published:             "Gepubliceerd",
+		Category:              "Categorie",
+		IntendedStatus:        "Beoogde Status",
+		Authors:               "Auteurs",
+		Phone:                 "Telefoon",
+		Email:                 "E-mail",
+		URI:                   "URI",
+		StandardsTrack:        "Standaard",
+		Informational:         "Informatief",
+		Experimental:          "Experimenteel",
+		BestCurrentPractice:   "Beste Huidige Praktijk",
+		FYI:                   "FYI",
+		And:                   "en",
+		EtAl:                  "e.a.",
+		Months: []string{"januari", "februari", "maart", "april", "mei", "juni", "juli", "augustus",
+			"september", "oktober", "november", "december"},
+		IndexReturn: "ga",
+		Search:      "Zoeken",
+	},
+	"de": {
+		Footnotes:             "Fußnoten",
+		Index:                 "Index",
+		TableOfContents:       "Inhaltsverzeichnis",
+		Contents:              "Inhalt",
+		Start:                 "Start",
+		NormativeReferences:   "Normative Referenzen",
+		InformativeReferences: "Informative Referenzen",
+		AuthorsAddresses:      "Adressen der Autoren",
+		AuthorsAddress:        "Adresse des Autors",
+		Section:               "Abschnitt",
+		Appendix:              "Anhang",
+		Figure:                "Abbildung",
+		Table:                 "Tabelle",
+		Of:                    "von",
+		Workgroup:             "Arbeitsgruppe",
+		Updates:               "Aktualisiert",
+		Obsoletes:             "Ersetzt",
+		Published:             "Veröffentlicht",
+		Category:              "Kategorie",
+		IntendedStatus:        "Angestrebter Status",
+		Authors:               "Autoren",
+		Phone:                 "Telefon",
+		Email:                 "E-Mail",
+		URI:                   "URI",
+		StandardsTrack:        "Standard",
+		Informational:         "Informativ",
+		Experimental:          "Experimentell",
+		BestCurrentPractice:   "Bewährte Praxis",
+		FYI:                   "FYI",
+		And:                   "und",
+		EtAl:                  "u. a.",
+		Months: []string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August",
+			"September", "Oktober", "November", "Dezember"},
+		IndexReturn: "zurück",
+		Search:      "Suchen",
+	},
+	"fr": {
+		Footnotes:             "Notes",
+		Index:                 "Index",
+		TableOfContents:       "Table des matières",
+		Contents:              "Sommaire",
+		Start:                 "Accueil",
+		NormativeReferences:   "Références normatives",
+		InformativeReferences: "Références informatives",
+		AuthorsAddresses:      "Adresses des auteurs",
+		AuthorsAddress:        "Adresse de l'auteur",
+		Section:               "Section",
+		Appendix:              "Annexe",
+		Figure:                "Figure",
+		Table:                 "Tableau",
+		Of:                    "de",
+		Workgroup:             "Groupe de travail",
+		Updates:               "Met à jour",
+		Obsoletes:             "Remplace",
+		Published:             "Publié",
+		Category:              "Catégorie",
+		IntendedStatus:        "Statut visé",
+		Authors:               "Auteurs",
+		Phone:                 "Téléphone",
+		Email:                 "Courriel",
+		URI:                   "URI",
+		StandardsTrack:        "Norme",
+		Informational:         "Informatif",
+		Experimental:          "Expérimental",
+		BestCurrentPractice:   "Meilleure pratique actuelle",
+		FYI:                   "FYI",
+		And:                   "et",
+		EtAl:                  "et al.",
+		Months: []string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août",
+			"septembre", "octobre", "novembre", "décembre"},
+		IndexReturn: "retour",
+		Search:      "Rechercher",
+	},
+}
+
+// New returns the labels for the language tag. A tag with a region, i.e. "de-CH", falls back to its
+// base language. For unknown languages the English labels are returned, with Tag still set to tag.
+func New(tag string) Lang {
+	l, ok := Languages[tag]
+	if !ok {
+		base := tag
+		if i := strings.IndexAny(tag, "-_"); i > 0 {
+			base = tag[:i]
+		}
+		l, ok = Languages[strings.ToLower(base)]
+	}
+	if !ok {
+		l = Languages["en"]
+	}
+	l.Tag = tag
+	if tag == "" {
+		l.Tag = "en"
+	}
+	l.Months = append([]string{}, l.Months...)
+	return l
+}
+
+// Load overrides the labels in l with the ones set in the TOML file, i.e. a file containing
+// `Footnotes = "Notes"` only changes the Footnotes label.
+func (l *Lang) Load(file string) error {
+	months := l.Months
+	if _, err := toml.DecodeFile(file, l); err != nil {
+		return err
+	}
+	if len(l.Months) != 12 {
+		n := len(l.Months)
+		l.Months = months
+		return fmt.Errorf("Months must have 12 entries, got %d", n)
+	}
+	return nil
+}
+
+// Status returns the text for the status of a document, as used in its series info, i.e. "standard"
+// is "Standards Track". For an unknown status the empty string is returned.
+func (l Lang) Status(status string) string {
+	switch status {
+	case "standard", "full-standard":
+		return l.StandardsTrack
+	case "informational":
+		return l.Informational
+	case "experimental":
+		return l.Experimental
+	case "bcp":
+		return l.BestCurrentPractice
+	case "fyi":
+		return l.FYI
+	}
+	return ""
+}
+
+// Month returns the name of month m, with 1 being January.
+func (l Lang) Month(m int) string {
+	if m < 1 || m > len(l.Months) {
+		return ""
+	}
+	return l.Months[m-1]
+}
@@ -0,0 +1,67 @@
+package lang
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	if l := New("nl"); l.Tag != "nl" || l.Footnotes != "Voetnoten" {
+		t.Errorf("want Dutch labels, got %q %q", l.Tag, l.Footnotes)
+	}
+	if l := New("sv"); l.Tag != "sv" || l.Footnotes != "Footnotes" {
+		t.Errorf("want English labels with tag sv, got %q %q", l.Tag, l.Footnotes)
+	}
+	if l := New("de-CH"); l.Tag != "de-CH" || l.Footnotes != Languages["de"].Footnotes {
+		t.Errorf("want German labels with tag de-CH, got %q %q", l.Tag, l.Footnotes)
+	}
+	if l := New("fr_CA"); l.Footnotes != Languages["fr"].Footnotes {
+		t.Errorf("want French labels for fr_CA, got %q", l.Footnotes)
+	}
+	if l := New(""); l.Tag != "en" {
+		t.Errorf("want tag en, got %q", l.Tag)
+	}
+	if s := New("nl").Status("informational"); s != "Informatief" {
+		t.Errorf("want %q, got %q", "Informatief", s)
+	}
+	if s := New("en").Status("full-standard"); s != "Standards Track" {
+		t.Errorf("want %q, got %q", "Standards Track", s)
+	}
+	for tag, l := range Languages {
+		if len(l.Months) != 12 {
+			t.Errorf("want 12 months for %s, got %d", tag, len(l.Months))
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lang")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "labels.toml")
+	if err := ioutil.WriteFile(file, []byte(`Footnotes = "Notes"`+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	l := New("en")
+	if err := l.Load(file); err != nil {
+		t.Fatal(err)
+	}
+	if l.Footnotes != "Notes" || l.Index != "Index" || l.Month(3) != "March" {
+		t.Errorf("want only Footnotes overridden, got %q %q %q", l.Footnotes, l.Index, l.Month(3))
+	}
+
+	if err := ioutil.WriteFile(file, []byte(`Months = ["jan"]`+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Load(file); err == nil {
+		t.Errorf("want error for too few months")
+	}
+	if l.Month(12) != "December" {
+		t.Errorf("want months to be unchanged, got %q", l.Month(12))
+	}
+}
@@ -0,0 +1,239 @@
+package latex
+
+import (
+	"strings"
+)
+
+// Art returns the LaTeX math in s as multiple lines of text, with fractions, scripts and limits laid
+// out in two dimensions. If ascii is true only ASCII is used.
+func Art(s string, ascii bool) (string, error) {
+	n, err := parse(s)
+	if err != nil {
+		return "", err
+	}
+	b := n.box(ascii)
+	lines := make([]string, len(b.lines))
+	for i, l := range b.lines {
+		lines[i] = strings.TrimRight(string(l), " ")
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// box is a rectangle of text, base is the line that aligns with the surrounding text.
+type box struct {
+	lines [][]rune
+	base  int
+}
+
+func textBox(s string) box { return box{lines: [][]rune{[]rune(s)}} }
+
+func (b box) width() int {
+	w := 0
+	for _, l := range b.lines {
+		if len(l) > w {
+			w = len(l)
+		}
+	}
+	return w
+}
+
+// pad returns the lines of b padded to width w, left is the number of spaces added on the left.
+func (b box) pad(w, left int) [][]rune {
+	lines := make([][]rune, len(b.lines))
+	for i, l := range b.lines {
+		line := []rune(strings.Repeat(" ", left))
+		line = append(line, l...)
+		for len(line) < w {
+			line = append(line, ' ')
+		}
+		lines[i] = line
+	}
+	return lines
+}
+
+// hcat puts boxes next to each other, aligned on their base lines.
+func hcat(boxes ...box) box {
+	above, below := 0, 0
+	for _, b := range boxes {
+		if b.base > above {
+			above = b.base
+		}
+		if d := len(b.lines) - b.base - 1; d > below {
+			below = d
+		}
+	}
+	lines := make([][]rune, above+below+1)
+	for _, b := range boxes {
+		w := b.width()
+		padded := b.pad(w, 0)
+		for i := range lines {
+			j := i - above + b.base
+			if j >= 0 && j < len(padded) {
+				lines[i] = append(lines[i], padded[j]...)
+			} else {
+				lines[i] = append(lines[i], []rune(strings.Repeat(" ", w))...)
+			}
+		}
+	}
+	return box{lines: lines, base: above}
+}
+
+// vcat stacks boxes centered on top of each other, base is the base line of boxes[base].
+func vcat(base int, boxes ...box) box {
+	w := 0
+	for _, b := range boxes {
+		if bw := b.width(); bw > w {
+			w = bw
+		}
+	}
+	v := box{}
+	for i, b := range boxes {
+		if i == base {
+			v.base = len(v.lines) + b.base
+		}
+		v.lines = append(v.lines, b.pad(w, (w-b.width())/2)...)
+	}
+	return v
+}
+
+func (n *node) box(ascii bool) box {
+	switch n.kind {
+	case kindRow:
+		boxes := []box{}
+		for i, k := range n.kids {
+			switch {
+			case k.kind == kindOp && k.spaced && unary(n.kids, i):
+				boxes = append(boxes, textBox(k.str(ascii)))
+				continue
+			case i > 0 && n.kids[i-1].function && k.kind != kindOp:
+				boxes = append(boxes, textBox(" "))
+			}
+			boxes = append(boxes, k.box(ascii))
+		}
+		if len(boxes) == 0 {
+			return textBox("")
+		}
+		return hcat(boxes...)
+	case kindFrac:
+		num, den := n.kids[0].box(ascii), n.kids[1].box(ascii)
+		if n.binom {
+			v := vcat(0, num, den)
+			return hcat(paren("(", v), v, paren(")", v))
+		}
+		w := num.width()
+		if dw := den.width(); dw > w {
+			w = dw
+		}
+		bar := "─"
+		if ascii {
+			bar = "-"
+		}
+		// The bar sticks out one character on both sides.
+		return vcat(1, num, textBox(strings.Repeat(bar, w+2)), den)
+	case kindSqrt:
+		if len(n.kids) > 1 || n.kids[0].flat() {
+			return textBox(n.linear(ascii))
+		}
+		root := "√"
+		if ascii {
+			root = "sqrt"
+		}
+		arg := n.kids[0].box(ascii)
+		return hcat(textBox(root), paren("(", arg), arg, paren(")", arg))
+	case kindScript:
+		if !ascii && !n.kids[0].limits && n.kids[0].flat() && n.unicodeScripts() {
+			return textBox(n.linear(ascii))
+		}
+		base := n.kids[0].box(ascii)
+		if n.kids[0].compound() {
+			base = hcat(paren("(", base), base, paren(")", base))
+		}
+		if n.kids[0].limits {
+			boxes := []box{}
+			i := 0
+			if n.sup != nil {
+				boxes = append(boxes, n.sup.box(ascii))
+				i = 1
+			}
+			boxes = append(boxes, base)
+			if n.sub != nil {
+				boxes = append(boxes, n.sub.box(ascii))
+			}
+			return vcat(i, boxes...)
+		}
+		return scripts(base, n.sub, n.sup, ascii)
+	}
+	return textBox(n.linear(ascii))
+}
+
+// flat returns true if n doesn't need more than one line.
+func (n *node) flat() bool {
+	switch n.kind {
+	case kindFrac:
+		return false
+	case kindSqrt:
+		return n.kids[0].flat()
+	case kindScript:
+		return false
+	case kindAccent:
+		return n.kids[0].flat()
+	}
+	for _, k := range n.kids {
+		if !k.flat() {
+			return false
+		}
+	}
+	return true
+}
+
+// unicodeScripts returns true if the scripts of n can be written with Unicode sub- and superscripts.
+func (n *node) unicodeScripts() bool {
+	if n.sub != nil && subscript(n.sub.linear(false)) == "" {
+		return false
+	}
+	return n.sup == nil || superscript(n.sup.linear(false)) != ""
+}
+
+// scripts puts sup above and to the right of base and sub below and to the right.
+func scripts(base box, sub, sup *node, ascii bool) box {
+	var sb, pb box
+	w := 0
+	if sub != nil {
+		sb = sub.box(ascii)
+		w = sb.width()
+	}
+	if sup != nil {
+		pb = sup.box(ascii)
+		if pw := pb.width(); pw > w {
+			w = pw
+		}
+	}
+	bw := base.width()
+	v := box{}
+	if sup != nil {
+		v.lines = append(v.lines, pb.pad(bw+w, bw)...)
+	}
+	v.base = len(v.lines) + base.base
+	v.lines = append(v.lines, base.pad(bw+w, 0)...)
+	if sub != nil {
+		v.lines = append(v.lines, sb.pad(bw+w, bw)...)
+	}
+	return v
+}
+
+// paren returns a parenthesis as high as b.
+func paren(p string, b box) box {
+	if len(b.lines) == 1 {
+		return textBox(p)
+	}
+	lines := make([][]rune, len(b.lines))
+	for i := range lines {
+		lines[i] = []rune("|")
+	}
+	if p == "(" {
+		lines[0], lines[len(lines)-1] = []rune("/"), []rune(`\`)
+	} else {
+		lines[0], lines[len(lines)-1] = []rune(`\`), []rune("/")
+	}
+	return box{lines: lines, base: b.base}
+}
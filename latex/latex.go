@@ -0,0 +1,370 @@
+// Package latex converts a subset of LaTeX math to MathML, to a single line of Unicode or ASCII text
+// and to Unicode or ASCII art.
+//
+// Supported are letters, numbers, operators, groups, sub- and superscripts, Greek letters, the common
+// operators and relations, \frac, \binom, \sqrt, \left and \right, \text, font commands like \mathbf
+// and accents like \hat. Environments (\begin) are not supported.
+package latex
+
+import (
+	"fmt"
+	"strings"
+)
+
+type kind int
+
+const (
+	kindIdent  kind = iota // identifier, i.e. x or \alpha
+	kindNumber             // number
+	kindOp                 // operator
+	kindText               // text
+	kindSpace              // space
+	kindRow                // sequence of nodes
+	kindFrac               // fraction, kids[0] over kids[1]
+	kindSqrt               // square root of kids[0], or the kids[1]-th root
+	kindScript             // kids[0] with sub and/or sup
+	kindAccent             // kids[0] with an accent above or below
+)
+
+type node struct {
+	kind    kind
+	text    string // Unicode text
+	ascii   string // ASCII text, if different from text
+	variant string // MathML mathvariant
+	kids    []*node
+	sub     *node
+	sup     *node
+
+	limits   bool // scripts go under and over in display mode, i.e. \sum
+	spaced   bool // operator is surrounded by spaces in text
+	function bool // function name, i.e. \sin
+	stretchy bool // stretchy delimiter, from \left and \right
+	binom    bool // fraction without bar, in parenthesis
+	under    bool // accent goes under
+}
+
+func (n *node) asciiText() string {
+	if n.ascii != "" {
+		return n.ascii
+	}
+	return n.text
+}
+
+type parser struct {
+	s string
+	i int
+}
+
+// parse parses the LaTeX math in s.
+func parse(s string) (*node, error) {
+	p := &parser{s: s}
+	n, err := p.row(0)
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// row parses a sequence of nodes up until end: '}' for a group, 'r' for \right, or 0 for the end
+// of input.
+func (p *parser) row(end byte) (*node, error) {
+	row := &node{kind: kindRow}
+	for {
+		p.skipSpace()
+		if p.i >= len(p.s) {
+			if end != 0 {
+				return nil, fmt.Errorf("missing %s", closing(end))
+			}
+			return row, nil
+		}
+
+		c := p.s[p.i]
+		switch {
+		case c == '}':
+			if end != '}' {
+				return nil, fmt.Errorf("unexpected } at offset %d", p.i)
+			}
+			p.i++
+			return row, nil
+		case strings.HasPrefix(p.s[p.i:], `\right`):
+			if end != 'r' {
+				return nil, fmt.Errorf(`unexpected \right at offset %d`, p.i)
+			}
+			return row, nil
+		case c == '^' || c == '_':
+			p.i++
+			script, err := p.arg()
+			if err != nil {
+				return nil, err
+			}
+			var base *node
+			if len(row.kids) > 0 {
+				base = row.kids[len(row.kids)-1]
+				row.kids = row.kids[:len(row.kids)-1]
+			} else {
+				base = &node{kind: kindRow}
+			}
+			if base.kind != kindScript || (c == '^' && base.sup != nil) || (c == '_' && base.sub != nil) {
+				base = &node{kind: kindScript, kids: []*node{base}}
+			}
+			if c == '^' {
+				base.sup = script
+			} else {
+				base.sub = script
+			}
+			row.kids = append(row.kids, base)
+		default:
+			n, err := p.atom()
+			if err != nil {
+				return nil, err
+			}
+			if n != nil {
+				row.kids = append(row.kids, n)
+			}
+		}
+	}
+}
+
+func closing(end byte) string {
+	if end == 'r' {
+		return `\right`
+	}
+	return string(end)
+}
+
+func (p *parser) skipSpace() {
+	for p.i < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.i]) >= 0 {
+		p.i++
+	}
+}
+
+// arg parses the argument of a command or script: a group or a single atom.
+func (p *parser) arg() (*node, error) {
+	p.skipSpace()
+	if p.i >= len(p.s) {
+		return nil, fmt.Errorf("missing argument at end of input")
+	}
+	n, err := p.atom()
+	if err != nil {
+		return nil, err
+	}
+	if n == nil {
+		n = &node{kind: kindRow}
+	}
+	return n, nil
+}
+
+// atom parses a single atom, it may return nil for things that don't render, like \!.
+func (p *parser) atom() (*node, error) {
+	c := p.s[p.i]
+	switch {
+	case c == '{':
+		p.i++
+		return p.row('}')
+	case c >= '0' && c <= '9' || c == '.' && p.i+1 < len(p.s) && p.s[p.i+1] >= '0' && p.s[p.i+1] <= '9':
+		start := p.i
+		for p.i < len(p.s) && (p.s[p.i] >= '0' && p.s[p.i] <= '9' || p.s[p.i] == '.') {
+			p.i++
+		}
+		return &node{kind: kindNumber, text: p.s[start:p.i]}, nil
+	case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		p.i++
+		return &node{kind: kindIdent, text: string(c)}, nil
+	case c == '\\':
+		return p.command()
+	case c == '\'':
+		p.i++
+		return &node{kind: kindOp, text: "′", ascii: "'"}, nil
+	}
+
+	p.i++
+	if o, ok := operators[string(c)]; ok {
+		n := *o
+		return &n, nil
+	}
+	if c >= 0x80 {
+		// Pass UTF-8 through as is.
+		start := p.i - 1
+		for p.i < len(p.s) && p.s[p.i]&0xC0 == 0x80 {
+			p.i++
+		}
+		return &node{kind: kindIdent, text: p.s[start:p.i]}, nil
+	}
+	return &node{kind: kindOp, text: string(c)}, nil
+}
+
+// command parses a command, starting at the backslash.
+func (p *parser) command() (*node, error) {
+	p.i++ // backslash
+	start := p.i
+	for p.i < len(p.s) && (p.s[p.i] >= 'a' && p.s[p.i] <= 'z' || p.s[p.i] >= 'A' && p.s[p.i] <= 'Z') {
+		p.i++
+	}
+	if p.i == start {
+		if p.i >= len(p.s) {
+			return nil, fmt.Errorf(`lone \ at end of input`)
+		}
+		p.i++
+	}
+	name := p.s[start:p.i]
+
+	if s, ok := spaces[name]; ok {
+		if s == "" {
+			return nil, nil
+		}
+		return &node{kind: kindSpace, text: s}, nil
+	}
+	if n, ok := escapes[name]; ok && len(name) == 1 {
+		c := *n
+		return &c, nil
+	}
+	if n, ok := symbols[name]; ok {
+		c := *n
+		return &c, nil
+	}
+	if n, ok := operators[name]; ok {
+		c := *n
+		return &c, nil
+	}
+	if limits, ok := functions[name]; ok {
+		return &node{kind: kindIdent, text: name, function: true, limits: limits}, nil
+	}
+	if a, ok := accents[name]; ok {
+		arg, err := p.arg()
+		if err != nil {
+			return nil, err
+		}
+		return &node{kind: kindAccent, text: a.text, ascii: name, under: a.under, kids: []*node{arg}}, nil
+	}
+	if v, ok := variants[name]; ok {
+		arg, err := p.arg()
+		if err != nil {
+			return nil, err
+		}
+		setVariant(arg, v)
+		return arg, nil
+	}
+
+	switch name {
+	case "frac", "dfrac", "tfrac", "binom":
+		num, err := p.arg()
+		if err != nil {
+			return nil, err
+		}
+		den, err := p.arg()
+		if err != nil {
+			return nil, err
+		}
+		return &node{kind: kindFrac, kids: []*node{num, den}, binom: name == "binom"}, nil
+	case "sqrt":
+		var index *node
+		p.skipSpace()
+		if p.i < len(p.s) && p.s[p.i] == '[' {
+			end := strings.IndexByte(p.s[p.i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf(`missing ] in \sqrt`)
+			}
+			var err error
+			if index, err = parse(p.s[p.i+1 : p.i+end]); err != nil {
+				return nil, err
+			}
+			p.i += end + 1
+		}
+		arg, err := p.arg()
+		if err != nil {
+			return nil, err
+		}
+		n := &node{kind: kindSqrt, kids: []*node{arg}}
+		if index != nil {
+			n.kids = append(n.kids, index)
+		}
+		return n, nil
+	case "text", "textrm", "mbox", "operatorname":
+		text, err := p.raw()
+		if err != nil {
+			return nil, err
+		}
+		if name == "operatorname" {
+			return &node{kind: kindIdent, text: text, function: true}, nil
+		}
+		return &node{kind: kindText, text: text}, nil
+	case "left":
+		open, err := p.delimiter()
+		if err != nil {
+			return nil, err
+		}
+		inner, err := p.row('r')
+		if err != nil {
+			return nil, err
+		}
+		p.i += len(`\right`)
+		close, err := p.delimiter()
+		if err != nil {
+			return nil, err
+		}
+		row := &node{kind: kindRow}
+		if open != nil {
+			row.kids = append(row.kids, open)
+		}
+		row.kids = append(row.kids, inner)
+		if close != nil {
+			row.kids = append(row.kids, close)
+		}
+		return row, nil
+	}
+	return nil, fmt.Errorf(`unsupported command \%s`, name)
+}
+
+// raw returns the unparsed contents of a {group}.
+func (p *parser) raw() (string, error) {
+	p.skipSpace()
+	if p.i >= len(p.s) || p.s[p.i] != '{' {
+		return "", fmt.Errorf("missing { at offset %d", p.i)
+	}
+	end := strings.IndexByte(p.s[p.i:], '}')
+	if end < 0 {
+		return "", fmt.Errorf("missing }")
+	}
+	text := p.s[p.i+1 : p.i+end]
+	p.i += end + 1
+	return text, nil
+}
+
+// delimiter parses the delimiter after \left or \right, "." is the empty delimiter and returns nil.
+func (p *parser) delimiter() (*node, error) {
+	p.skipSpace()
+	if p.i >= len(p.s) {
+		return nil, fmt.Errorf("missing delimiter")
+	}
+	if p.s[p.i] == '.' {
+		p.i++
+		return nil, nil
+	}
+	n, err := p.atom()
+	if err != nil {
+		return nil, err
+	}
+	if n == nil || (n.kind != kindOp && n.kind != kindIdent) {
+		return nil, fmt.Errorf("invalid delimiter at offset %d", p.i)
+	}
+	n.kind = kindOp
+	n.stretchy = true
+	n.spaced = false
+	return n, nil
+}
+
+func setVariant(n *node, v variant) {
+	switch n.kind {
+	case kindIdent, kindNumber:
+		n.variant = v.name
+		if v.letters != nil {
+			if r, ok := v.letters[n.text]; ok {
+				n.ascii = n.text
+				n.text = r
+			}
+		}
+	}
+	for _, k := range n.kids {
+		setVariant(k, v)
+	}
+}
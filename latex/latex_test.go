@@ -0,0 +1,97 @@
+package latex
+
+import (
+	"testing"
+)
+
+func TestMathML(t *testing.T) {
+	tests := []struct {
+		in      string
+		display bool
+		out     string
+	}{
+		{`x^2`, false, `<msup><mi>x</mi><mn>2</mn></msup>`},
+		{`a + \alpha`, false, `<mrow><mi>a</mi><mo>+</mo><mi>α</mi></mrow>`},
+		{`\frac{a}{b}`, false, `<mfrac><mi>a</mi><mi>b</mi></mfrac>`},
+		{`\sqrt[3]{x}`, false, `<mroot><mi>x</mi><mn>3</mn></mroot>`},
+		{`\sum_{i=1}^n i`, true, `<mrow><munderover><mo>∑</mo><mrow><mi>i</mi><mo>=</mo><mn>1</mn></mrow><mi>n</mi></munderover><mi>i</mi></mrow>`},
+		{`\sum_{i=1}^n i`, false, `<mrow><msubsup><mo>∑</mo><mrow><mi>i</mi><mo>=</mo><mn>1</mn></mrow><mi>n</mi></msubsup><mi>i</mi></mrow>`},
+		{`\mathbb{R}`, false, `<mi mathvariant="double-struck">ℝ</mi>`},
+		{`\left( x \right)`, false, `<mrow><mo stretchy="true">(</mo><mi>x</mi><mo stretchy="true">)</mo></mrow>`},
+		{`a < b`, false, `<mrow><mi>a</mi><mo>&lt;</mo><mi>b</mi></mrow>`},
+	}
+	for i, tc := range tests {
+		out, err := MathML(tc.in, tc.display)
+		if err != nil {
+			t.Errorf("test %d, got error %s", i, err)
+			continue
+		}
+		want := `<math xmlns="http://www.w3.org/1998/Math/MathML">`
+		if tc.display {
+			want = `<math xmlns="http://www.w3.org/1998/Math/MathML" display="block">`
+		}
+		want += tc.out + "</math>"
+		if string(out) != want {
+			t.Errorf("test %d, got %q, want %q", i, out, want)
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		in    string
+		ascii bool
+		out   string
+	}{
+		{`x^2 + \alpha`, false, "x² + α"},
+		{`x^2 + \alpha`, true, "x^2 + alpha"},
+		{`x_{i+1}`, false, "xᵢ₊₁"},
+		{`x^{ab}`, false, "x^(ab)"},
+		{`\frac{a+b}{c}`, false, "(a + b)/c"},
+		{`\sqrt{2}`, true, "sqrt(2)"},
+		{`-x \le y`, true, "-x <= y"},
+		{`\sin x`, false, "sin x"},
+		{`\sum_{i=0}^{n} i^2`, true, "sum_(i = 0)^n i^2"},
+		{`\sum_{i=0}^{n} i^2`, false, "∑ᵢ₌₀ⁿ i²"},
+	}
+	for i, tc := range tests {
+		out, err := Text(tc.in, tc.ascii)
+		if err != nil {
+			t.Errorf("test %d, got error %s", i, err)
+			continue
+		}
+		if out != tc.out {
+			t.Errorf("test %d, got %q, want %q", i, out, tc.out)
+		}
+	}
+}
+
+func TestArt(t *testing.T) {
+	tests := []struct {
+		in    string
+		ascii bool
+		out   string
+	}{
+		{`\frac{a}{b} = c`, true, " a\n--- = c\n b"},
+		{`x^2`, true, " 2\nx"},
+		{`x^2`, false, "x²"},
+	}
+	for i, tc := range tests {
+		out, err := Art(tc.in, tc.ascii)
+		if err != nil {
+			t.Errorf("test %d, got error %s", i, err)
+			continue
+		}
+		if out != tc.out {
+			t.Errorf("test %d, got %q, want %q", i, out, tc.out)
+		}
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	for _, in := range []string{`\begin{matrix}`, `{x`, `x}`, `\frac{a}`} {
+		if _, err := MathML(in, false); err == nil {
+			t.Errorf("expected error for %q", in)
+		}
+	}
+}
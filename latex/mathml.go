@@ -0,0 +1,137 @@
+package latex
+
+import (
+	"bytes"
+	"strings"
+)
+
+// MathML returns the LaTeX math in s as a MathML <math> element. If display is true the math is
+// rendered as a block.
+func MathML(s string, display bool) ([]byte, error) {
+	n, err := parse(s)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString(`<math xmlns="http://www.w3.org/1998/Math/MathML"`)
+	if display {
+		buf.WriteString(` display="block"`)
+	}
+	buf.WriteString(">")
+	n.mathml(buf, display)
+	buf.WriteString("</math>")
+	return buf.Bytes(), nil
+}
+
+var escaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+func element(buf *bytes.Buffer, tag, attrs, text string) {
+	buf.WriteString("<" + tag + attrs + ">")
+	buf.WriteString(escaper.Replace(text))
+	buf.WriteString("</" + tag + ">")
+}
+
+func (n *node) mathml(buf *bytes.Buffer, display bool) {
+	switch n.kind {
+	case kindIdent:
+		attrs := ""
+		if n.variant != "" {
+			attrs = ` mathvariant="` + n.variant + `"`
+		}
+		element(buf, "mi", attrs, n.text)
+	case kindNumber:
+		element(buf, "mn", "", n.text)
+	case kindOp:
+		attrs := ""
+		if n.stretchy {
+			attrs = ` stretchy="true"`
+		}
+		element(buf, "mo", attrs, n.text)
+	case kindText:
+		element(buf, "mtext", "", n.text)
+	case kindSpace:
+		width := "0.3em"
+		switch len(n.text) {
+		case 2:
+			width = "1em"
+		case 4:
+			width = "2em"
+		}
+		buf.WriteString(`<mspace width="` + width + `"/>`)
+	case kindRow:
+		if len(n.kids) == 1 {
+			n.kids[0].mathml(buf, display)
+			return
+		}
+		buf.WriteString("<mrow>")
+		for _, k := range n.kids {
+			k.mathml(buf, display)
+		}
+		buf.WriteString("</mrow>")
+	case kindFrac:
+		if n.binom {
+			buf.WriteString(`<mrow><mo>(</mo><mfrac linethickness="0">`)
+		} else {
+			buf.WriteString("<mfrac>")
+		}
+		n.kids[0].mathml(buf, display)
+		n.kids[1].mathml(buf, display)
+		buf.WriteString("</mfrac>")
+		if n.binom {
+			buf.WriteString("<mo>)</mo></mrow>")
+		}
+	case kindSqrt:
+		if len(n.kids) == 1 {
+			buf.WriteString("<msqrt>")
+			n.kids[0].mathml(buf, display)
+			buf.WriteString("</msqrt>")
+			return
+		}
+		buf.WriteString("<mroot>")
+		n.kids[0].mathml(buf, display)
+		n.kids[1].mathml(buf, display)
+		buf.WriteString("</mroot>")
+	case kindScript:
+		tag := ""
+		limits := display && n.kids[0].limits
+		switch {
+		case n.sub != nil && n.sup != nil:
+			tag = "msubsup"
+			if limits {
+				tag = "munderover"
+			}
+		case n.sub != nil:
+			tag = "msub"
+			if limits {
+				tag = "munder"
+			}
+		default:
+			tag = "msup"
+			if limits {
+				tag = "mover"
+			}
+		}
+		buf.WriteString("<" + tag + ">")
+		n.kids[0].mathml(buf, display)
+		if n.sub != nil {
+			n.sub.mathml(buf, display)
+		}
+		if n.sup != nil {
+			n.sup.mathml(buf, display)
+		}
+		buf.WriteString("</" + tag + ">")
+	case kindAccent:
+		if n.under {
+			buf.WriteString(`<munder accentunder="true">`)
+			n.kids[0].mathml(buf, display)
+			element(buf, "mo", "", n.text)
+			buf.WriteString("</munder>")
+			return
+		}
+		buf.WriteString(`<mover accent="true">`)
+		n.kids[0].mathml(buf, display)
+		element(buf, "mo", "", n.text)
+		buf.WriteString("</mover>")
+	}
+}
@@ -0,0 +1,119 @@
+package latex
+
+// symbols are the commands that are identifiers.
+var symbols = map[string]*node{
+	"alpha": ident("α", "alpha"), "beta": ident("β", "beta"), "gamma": ident("γ", "gamma"),
+	"delta": ident("δ", "delta"), "epsilon": ident("ϵ", "epsilon"), "varepsilon": ident("ε", "epsilon"),
+	"zeta": ident("ζ", "zeta"), "eta": ident("η", "eta"), "theta": ident("θ", "theta"),
+	"vartheta": ident("ϑ", "theta"), "iota": ident("ι", "iota"), "kappa": ident("κ", "kappa"),
+	"lambda": ident("λ", "lambda"), "mu": ident("μ", "mu"), "nu": ident("ν", "nu"), "xi": ident("ξ", "xi"),
+	"pi": ident("π", "pi"), "varpi": ident("ϖ", "pi"), "rho": ident("ρ", "rho"), "varrho": ident("ϱ", "rho"),
+	"sigma": ident("σ", "sigma"), "varsigma": ident("ς", "sigma"), "tau": ident("τ", "tau"),
+	"upsilon": ident("υ", "upsilon"), "phi": ident("ϕ", "phi"), "varphi": ident("φ", "phi"),
+	"chi": ident("χ", "chi"), "psi": ident("ψ", "psi"), "omega": ident("ω", "omega"),
+	"Gamma": ident("Γ", "Gamma"), "Delta": ident("Δ", "Delta"), "Theta": ident("Θ", "Theta"),
+	"Lambda": ident("Λ", "Lambda"), "Xi": ident("Ξ", "Xi"), "Pi": ident("Π", "Pi"), "Sigma": ident("Σ", "Sigma"),
+	"Upsilon": ident("Υ", "Upsilon"), "Phi": ident("Φ", "Phi"), "Psi": ident("Ψ", "Psi"), "Omega": ident("Ω", "Omega"),
+
+	"infty": ident("∞", "inf"), "partial": ident("∂", "d"), "nabla": ident("∇", "nabla"),
+	"emptyset": ident("∅", "{}"), "ell": ident("ℓ", "l"), "hbar": ident("ℏ", "hbar"), "aleph": ident("ℵ", "aleph"),
+}
+
+// operators are the operators, both as single characters and as commands.
+var operators = map[string]*node{
+	"+": spaced("+", ""), "-": spaced("−", "-"), "=": spaced("=", ""), "<": spaced("<", ""), ">": spaced(">", ""),
+	"*": spaced("∗", "*"), "/": op("/", ""), ",": op(",", ""), ";": op(";", ""), ":": spaced(":", ""),
+	"!": op("!", ""), "(": op("(", ""), ")": op(")", ""), "[": op("[", ""), "]": op("]", ""), "|": op("|", ""),
+
+	"langle": op("⟨", "<"), "rangle": op("⟩", ">"), "lfloor": op("⌊", "|_"), "rfloor": op("⌋", "_|"),
+	"lceil": op("⌈", "|^"), "rceil": op("⌉", "^|"), "lvert": op("|", ""), "rvert": op("|", ""),
+
+	"cdot": spaced("·", "*"), "times": spaced("×", "x"), "div": spaced("÷", "/"), "pm": spaced("±", "+-"),
+	"mp": spaced("∓", "-+"), "ast": spaced("∗", "*"), "star": spaced("⋆", "*"), "circ": spaced("∘", "o"),
+	"oplus": spaced("⊕", "(+)"), "otimes": spaced("⊗", "(x)"), "wedge": spaced("∧", "/\\"),
+	"vee": spaced("∨", "\\/"), "land": spaced("∧", "/\\"), "lor": spaced("∨", "\\/"), "neg": op("¬", "~"),
+	"lnot": op("¬", "~"), "setminus": spaced("∖", "\\"), "cup": spaced("∪", "U"), "cap": spaced("∩", "^"),
+
+	"le": spaced("≤", "<="), "leq": spaced("≤", "<="), "ge": spaced("≥", ">="), "geq": spaced("≥", ">="),
+	"ne": spaced("≠", "!="), "neq": spaced("≠", "!="), "approx": spaced("≈", "~="), "equiv": spaced("≡", "=="),
+	"sim": spaced("∼", "~"), "simeq": spaced("≃", "~="), "cong": spaced("≅", "~="), "propto": spaced("∝", "~"),
+	"ll": spaced("≪", "<<"), "gg": spaced("≫", ">>"), "in": spaced("∈", "in"), "notin": spaced("∉", "not in"),
+	"ni": spaced("∋", "contains"), "subset": spaced("⊂", "subset"), "subseteq": spaced("⊆", "subseteq"),
+	"supset": spaced("⊃", "supset"), "supseteq": spaced("⊇", "supseteq"), "mid": spaced("∣", "|"),
+	"parallel": spaced("∥", "||"), "perp": spaced("⊥", "_|_"),
+
+	"to": spaced("→", "->"), "rightarrow": spaced("→", "->"), "leftarrow": spaced("←", "<-"),
+	"gets": spaced("←", "<-"), "leftrightarrow": spaced("↔", "<->"), "Rightarrow": spaced("⇒", "=>"),
+	"Leftarrow": spaced("⇐", "<="), "Leftrightarrow": spaced("⇔", "<=>"), "implies": spaced("⟹", "=>"),
+	"iff": spaced("⟺", "<=>"), "mapsto": spaced("↦", "|->"),
+
+	"forall": op("∀", "for all "), "exists": op("∃", "exists "), "ldots": op("…", "..."),
+	"cdots": op("⋯", "..."), "dots": op("…", "..."), "vdots": op("⋮", ":"), "prime": op("′", "'"),
+
+	"sum": bigop("∑", "sum", true), "prod": bigop("∏", "prod", true), "coprod": bigop("∐", "coprod", true),
+	"bigcup": bigop("⋃", "U", true), "bigcap": bigop("⋂", "^", true), "int": bigop("∫", "int", false),
+	"iint": bigop("∬", "iint", false), "oint": bigop("∮", "oint", false),
+}
+
+// escapes are the commands for characters that have a special meaning in LaTeX.
+var escapes = map[string]*node{
+	"{": op("{", ""), "}": op("}", ""), "|": op("‖", "||"), "_": ident("_", ""), "%": op("%", ""),
+	"&": op("&", ""), "#": op("#", ""), "$": op("$", ""),
+}
+
+// functions are the function names, the value tells if scripts go under and over.
+var functions = map[string]bool{
+	"sin": false, "cos": false, "tan": false, "cot": false, "sec": false, "csc": false, "arcsin": false,
+	"arccos": false, "arctan": false, "sinh": false, "cosh": false, "tanh": false, "log": false, "ln": false,
+	"lg": false, "exp": false, "deg": false, "arg": false, "dim": false, "ker": false, "hom": false,
+	"lim": true, "liminf": true, "limsup": true, "min": true, "max": true, "sup": true, "inf": true,
+	"det": true, "gcd": true, "Pr": true,
+}
+
+type accent struct {
+	text  string
+	under bool
+}
+
+// accents are the commands that put an accent on their argument.
+var accents = map[string]accent{
+	"hat": {"^", false}, "widehat": {"^", false}, "bar": {"¯", false}, "overline": {"¯", false},
+	"vec": {"→", false}, "dot": {"˙", false}, "ddot": {"¨", false}, "tilde": {"~", false},
+	"widetilde": {"~", false}, "underline": {"_", true},
+}
+
+type variant struct {
+	name    string
+	letters map[string]string
+}
+
+// variants are the font commands.
+var variants = map[string]variant{
+	"mathrm":   {name: "normal"},
+	"mathit":   {name: "italic"},
+	"mathbf":   {name: "bold"},
+	"mathsf":   {name: "sans-serif"},
+	"mathtt":   {name: "monospace"},
+	"mathcal":  {name: "script"},
+	"mathfrak": {name: "fraktur"},
+	"mathbb": {name: "double-struck", letters: map[string]string{
+		"C": "ℂ", "H": "ℍ", "N": "ℕ", "P": "ℙ", "Q": "ℚ", "R": "ℝ", "Z": "ℤ",
+	}},
+}
+
+// spaces are the spacing commands, the empty string is a negative space that is ignored.
+var spaces = map[string]string{
+	",": " ", ":": " ", ";": " ", " ": " ", "quad": "  ", "qquad": "    ", "\\": " ", "!": "",
+}
+
+func ident(text, ascii string) *node { return &node{kind: kindIdent, text: text, ascii: ascii} }
+
+func op(text, ascii string) *node { return &node{kind: kindOp, text: text, ascii: ascii} }
+
+func spaced(text, ascii string) *node {
+	return &node{kind: kindOp, text: text, ascii: ascii, spaced: true}
+}
+
+func bigop(text, ascii string, limits bool) *node {
+	return &node{kind: kindOp, text: text, ascii: ascii, limits: limits}
+}
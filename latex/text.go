@@ -0,0 +1,176 @@
+package latex
+
+import (
+	"strings"
+)
+
+// Text returns the LaTeX math in s as a single line of text. If ascii is true only ASCII is used,
+// otherwise Unicode symbols, sub- and superscripts are used where possible.
+func Text(s string, ascii bool) (string, error) {
+	n, err := parse(s)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(strings.Fields(n.linear(ascii)), " "), nil
+}
+
+func (n *node) str(ascii bool) string {
+	if ascii {
+		return n.asciiText()
+	}
+	return n.text
+}
+
+func (n *node) linear(ascii bool) string {
+	switch n.kind {
+	case kindIdent, kindNumber, kindText:
+		return n.str(ascii)
+	case kindOp:
+		if n.spaced {
+			return " " + n.str(ascii) + " "
+		}
+		if n.text == "," || n.text == ";" {
+			return n.text + " "
+		}
+		return n.str(ascii)
+	case kindSpace:
+		return " "
+	case kindRow:
+		b := &strings.Builder{}
+		for i, k := range n.kids {
+			switch {
+			case k.kind == kindOp && k.spaced && unary(n.kids, i):
+				b.WriteString(k.str(ascii))
+				continue
+			case i > 0 && (n.kids[i-1].function || n.kids[i-1].kind == kindScript) && k.kind != kindOp:
+				// Separate the operand from a function or a script, i.e. "sum_(i = 0)^n i".
+				b.WriteString(" ")
+			}
+			b.WriteString(k.linear(ascii))
+		}
+		return b.String()
+	case kindFrac:
+		if n.binom {
+			return "binom(" + n.kids[0].linear(ascii) + ", " + n.kids[1].linear(ascii) + ")"
+		}
+		return n.kids[0].group(ascii) + "/" + n.kids[1].group(ascii)
+	case kindSqrt:
+		root := "√"
+		if ascii {
+			root = "sqrt"
+		}
+		if len(n.kids) > 1 {
+			index := n.kids[1].linear(ascii)
+			switch {
+			case ascii:
+				root = "root" + index
+			case index == "3":
+				root = "∛"
+			case index == "4":
+				root = "∜"
+			default:
+				root = superscript(index) + root
+			}
+		}
+		return root + "(" + n.kids[0].linear(ascii) + ")"
+	case kindScript:
+		s := n.kids[0].group(ascii)
+		if n.sub != nil {
+			s += script(n.sub, "_", ascii, subscript)
+		}
+		if n.sup != nil {
+			s += script(n.sup, "^", ascii, superscript)
+		}
+		return s
+	case kindAccent:
+		base := n.kids[0].linear(ascii)
+		if c, ok := combining[n.ascii]; ok && !ascii && len([]rune(base)) == 1 {
+			return base + c
+		}
+		return n.ascii + "(" + base + ")"
+	}
+	return ""
+}
+
+// unary returns true if the operator kids[i] is a unary + or -.
+func unary(kids []*node, i int) bool {
+	if kids[i].text != "+" && kids[i].text != "−" && kids[i].text != "±" {
+		return false
+	}
+	return i == 0 || kids[i-1].kind == kindOp && kids[i-1].text != ")" && kids[i-1].text != "]"
+}
+
+// group returns the text of n, in parenthesis if it consists of more than one element.
+func (n *node) group(ascii bool) string {
+	s := n.linear(ascii)
+	if n.compound() {
+		return "(" + s + ")"
+	}
+	return s
+}
+
+func (n *node) compound() bool {
+	switch n.kind {
+	case kindFrac:
+		return !n.binom
+	case kindRow:
+		if len(n.kids) < 2 {
+			return len(n.kids) == 1 && n.kids[0].compound()
+		}
+		first, last := n.kids[0], n.kids[len(n.kids)-1]
+		return !(first.kind == kindOp && last.kind == kindOp && first.text == "(" && last.text == ")")
+	}
+	return false
+}
+
+// script returns the text of a sub- or superscript, using the Unicode characters from conv if possible.
+func script(n *node, mark string, ascii bool, conv func(string) string) string {
+	s := n.linear(ascii)
+	if !ascii {
+		if c := conv(s); c != "" {
+			return c
+		}
+	}
+	if n.compound() || len([]rune(s)) > 1 {
+		return mark + "(" + s + ")"
+	}
+	return mark + s
+}
+
+var (
+	superscripts = map[rune]rune{
+		'0': '⁰', '1': '¹', '2': '²', '3': '³', '4': '⁴', '5': '⁵', '6': '⁶', '7': '⁷', '8': '⁸', '9': '⁹',
+		'+': '⁺', '−': '⁻', '=': '⁼', '(': '⁽', ')': '⁾', 'n': 'ⁿ', 'i': 'ⁱ', '′': '′',
+	}
+	subscripts = map[rune]rune{
+		'0': '₀', '1': '₁', '2': '₂', '3': '₃', '4': '₄', '5': '₅', '6': '₆', '7': '₇', '8': '₈', '9': '₉',
+		'+': '₊', '−': '₋', '=': '₌', '(': '₍', ')': '₎', 'a': 'ₐ', 'e': 'ₑ', 'o': 'ₒ', 'x': 'ₓ', 'h': 'ₕ',
+		'k': 'ₖ', 'l': 'ₗ', 'm': 'ₘ', 'n': 'ₙ', 'p': 'ₚ', 's': 'ₛ', 't': 'ₜ', 'i': 'ᵢ', 'j': 'ⱼ', 'r': 'ᵣ',
+		'u': 'ᵤ', 'v': 'ᵥ',
+	}
+	combining = map[string]string{
+		"hat": "̂", "widehat": "̂", "bar": "̄", "overline": "̅", "vec": "⃗",
+		"dot": "̇", "ddot": "̈", "tilde": "̃", "widetilde": "̃", "underline": "̲",
+	}
+)
+
+func superscript(s string) string { return convert(s, superscripts) }
+
+func subscript(s string) string { return convert(s, subscripts) }
+
+// convert converts all runes in s with m, if a rune can't be converted the empty string is returned.
+func convert(s string, m map[rune]rune) string {
+	s = strings.Replace(s, " ", "", -1)
+	if s == "" {
+		return ""
+	}
+	out := make([]rune, 0, len(s))
+	for _, r := range s {
+		c, ok := m[r]
+		if !ok {
+			return ""
+		}
+		out = append(out, c)
+	}
+	return string(out)
+}
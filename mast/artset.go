@@ -0,0 +1,13 @@
+package mast
+
+import "github.com/gomarkdown/markdown/ast"
+
+// ArtSet holds two renditions of the same artwork: an SVG image and its ascii-art. The ascii-art is
+// the only child, an *ast.CodeBlock.
+type ArtSet struct {
+	ast.Container
+
+	SVG []byte // The <svg> element.
+	Src []byte // File the SVG is read from.
+	Alt []byte // Alternative text of the SVG image.
+}
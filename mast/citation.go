@@ -0,0 +1,75 @@
+package mast
+
+import (
+	"strings"
+)
+
+// Locator points to a section or appendix of a cited document, it's parsed from the suffix of an
+// ast.Citation: "[@RFC8446, section 4.2]" or "[@RFC8446, appendix B, comma]".
+type Locator struct {
+	Section  string // Section number or appendix letter, i.e. "4.2" or "B".
+	Appendix bool   // Section is an appendix.
+	Format   string // The sectionFormat of RFC 7991: "of" (the default), "comma", "parens" or "bare".
+}
+
+// LocatorFormats are the allowed formats of a Locator.
+var LocatorFormats = map[string]bool{"of": true, "comma": true, "parens": true, "bare": true}
+
+// CitationLocator parses the locator in the citation suffix. The locator is "section", "sec." or
+// "§" followed by a section number, or "appendix" or "app." followed by an appendix. It may be
+// followed by a comma and a format, see LocatorFormats. If suffix isn't a locator nil is returned.
+func CitationLocator(suffix []byte) *Locator {
+	parts := strings.Split(string(suffix), ",")
+	if len(parts) > 2 {
+		return nil
+	}
+	loc := &Locator{Format: "of"}
+	if len(parts) == 2 {
+		loc.Format = strings.ToLower(strings.TrimSpace(parts[1]))
+		if !LocatorFormats[loc.Format] {
+			return nil
+		}
+	}
+
+	locator := strings.TrimSpace(parts[0])
+	if strings.HasPrefix(locator, "§") {
+		locator = "section " + strings.TrimPrefix(locator, "§")
+	}
+	fields := strings.Fields(locator)
+	if len(fields) != 2 {
+		return nil
+	}
+	switch strings.ToLower(fields[0]) {
+	case "section", "sec.":
+	case "appendix", "app.":
+		loc.Appendix = true
+	default:
+		return nil
+	}
+	loc.Section = strings.TrimSuffix(fields[1], ".")
+	if loc.Section == "" || strings.Trim(loc.Section, "0123456789.-abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return nil
+	}
+	return loc
+}
+
+// Text returns the locator as text, using the label section or appendix, i.e. "Section 4.2". A bare
+// locator is only the section number.
+func (l *Locator) Text(section, appendix string) string {
+	if l.Format == "bare" {
+		return l.Section
+	}
+	if l.Appendix {
+		return appendix + " " + l.Section
+	}
+	return section + " " + l.Section
+}
+
+// Fragment returns the fragment identifier the RFC Editor uses for the section, i.e. "section-4.2"
+// or "appendix-B".
+func (l *Locator) Fragment() string {
+	if l.Appendix {
+		return "appendix-" + l.Section
+	}
+	return "section-" + l.Section
+}
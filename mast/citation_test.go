@@ -0,0 +1,36 @@
+package mast
+
+import "testing"
+
+func TestCitationLocator(t *testing.T) {
+	tests := []struct {
+		in   string
+		want *Locator
+	}{
+		{"section 4.2", &Locator{Section: "4.2", Format: "of"}},
+		{"Sec. 4.2.", &Locator{Section: "4.2", Format: "of"}},
+		{"§4.2", &Locator{Section: "4.2", Format: "of"}},
+		{"appendix B", &Locator{Section: "B", Appendix: true, Format: "of"}},
+		{"section 3, parens", &Locator{Section: "3", Format: "parens"}},
+		{"app. A.1, Bare", &Locator{Section: "A.1", Appendix: true, Format: "bare"}},
+		{"", nil},
+		{"p. 12", nil},
+		{"section 3, above", nil},
+		{"section <3>", nil},
+		{"section 3, comma, of", nil},
+	}
+	for _, tc := range tests {
+		got := CitationLocator([]byte(tc.in))
+		if (got == nil) != (tc.want == nil) || (got != nil && *got != *tc.want) {
+			t.Errorf("want %+v, got %+v, for input %q", tc.want, got, tc.in)
+		}
+	}
+
+	loc := &Locator{Section: "B", Appendix: true, Format: "comma"}
+	if text := loc.Text("Section", "Appendix"); text != "Appendix B" {
+		t.Errorf("want %q, got %q", "Appendix B", text)
+	}
+	if frag := loc.Fragment(); frag != "appendix-B" {
+		t.Errorf("want %q, got %q", "appendix-B", frag)
+	}
+}
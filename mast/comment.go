@@ -0,0 +1,11 @@
+package mast
+
+import "github.com/gomarkdown/markdown/ast"
+
+// Comment is an editorial comment, i.e. "[[ alice: fix this ]]". The children are the text of the
+// comment.
+type Comment struct {
+	ast.Container
+
+	Source []byte // Who made the comment, i.e. "alice", may be empty.
+}
@@ -0,0 +1,100 @@
+package mast
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Date is a, possibly partial, date from the title block. RFC 7991 allows the year, month and day to
+// be specified independently, see https://tools.ietf.org/html/rfc7991#section-2.17. Components
+// that are not specified are zero.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// DateToday is the value of date in the title block that is replaced with the current date. If the
+// environment variable SOURCE_DATE_EPOCH is set it is used instead of the current time, see
+// https://reproducible-builds.org/specs/source-date-epoch/.
+const DateToday = "today"
+
+// NewDate returns a Date with all components set from t.
+func NewDate(t time.Time) Date { return Date{Year: t.Year(), Month: t.Month(), Day: t.Day()} }
+
+// IsZero returns true if no component of the date is specified.
+func (d Date) IsZero() bool { return d.Year == 0 && d.Month == 0 && d.Day == 0 }
+
+// Time returns d as a time.Time, components that are not specified are set to their lowest value.
+func (d Date) Time() time.Time {
+	month, day := d.Month, d.Day
+	if month == 0 {
+		month = time.January
+	}
+	if day == 0 {
+		day = 1
+	}
+	return time.Date(d.Year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// String returns the date in ISO 8601 format, only the specified components are included, i.e.
+// "2018", "2018-07" or "2018-07-22".
+func (d Date) String() string {
+	switch {
+	case d.IsZero():
+		return ""
+	case d.Month == 0:
+		return fmt.Sprintf("%04d", d.Year)
+	case d.Day == 0:
+		return fmt.Sprintf("%04d-%02d", d.Year, d.Month)
+	}
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It parses "today", a full TOML datetime, or
+// a (partial) ISO 8601 date: "2018", "2018-07" or "2018-07-22".
+func (d *Date) UnmarshalText(text []byte) error {
+	s := strings.TrimSpace(string(text))
+	if s == "" {
+		*d = Date{}
+		return nil
+	}
+
+	if s == DateToday {
+		now := time.Now().UTC()
+		if epoch := os.Getenv("SOURCE_DATE_EPOCH"); epoch != "" {
+			sec, err := strconv.ParseInt(epoch, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid SOURCE_DATE_EPOCH %q: %s", epoch, err)
+			}
+			now = time.Unix(sec, 0).UTC()
+		}
+		*d = NewDate(now)
+		return nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		*d = NewDate(t)
+		return nil
+	}
+
+	layouts := []string{"2006-01-02", "2006-01", "2006"}
+	for i, layout := range layouts {
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			continue
+		}
+		*d = Date{Year: t.Year()}
+		if i < 2 {
+			d.Month = t.Month()
+		}
+		if i < 1 {
+			d.Day = t.Day()
+		}
+		return nil
+	}
+	return fmt.Errorf("invalid date %q", s)
+}
@@ -0,0 +1,63 @@
+package mast
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestDateUnmarshalText(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Date
+	}{
+		{"2018", Date{Year: 2018}},
+		{"2018-07", Date{Year: 2018, Month: time.July}},
+		{"2018-07-22", Date{Year: 2018, Month: time.July, Day: 22}},
+		{"2014-12-10T00:00:00Z", Date{Year: 2014, Month: time.December, Day: 10}},
+		{"", Date{}},
+	}
+	for _, tc := range tests {
+		var d Date
+		if err := d.UnmarshalText([]byte(tc.in)); err != nil {
+			t.Errorf("failed to parse %q: %s", tc.in, err)
+			continue
+		}
+		if d != tc.want {
+			t.Errorf("want %v, got %v, for input %q", tc.want, d, tc.in)
+		}
+	}
+
+	var d Date
+	if err := d.UnmarshalText([]byte("2018/07")); err == nil {
+		t.Errorf("expected error for input %q", "2018/07")
+	}
+}
+
+func TestDateToday(t *testing.T) {
+	defer os.Unsetenv("SOURCE_DATE_EPOCH")
+	os.Setenv("SOURCE_DATE_EPOCH", "1532217600") // 2018-07-22T00:00:00Z
+
+	var d Date
+	if err := d.UnmarshalText([]byte(DateToday)); err != nil {
+		t.Fatalf("failed to parse %q: %s", DateToday, err)
+	}
+	want := Date{Year: 2018, Month: time.July, Day: 22}
+	if d != want {
+		t.Errorf("want %v, got %v", want, d)
+	}
+}
+
+func TestDateString(t *testing.T) {
+	tests := map[string]Date{
+		"2018":       {Year: 2018},
+		"2018-07":    {Year: 2018, Month: time.July},
+		"2018-07-02": {Year: 2018, Month: time.July, Day: 2},
+		"":           {},
+	}
+	for want, d := range tests {
+		if got := d.String(); got != want {
+			t.Errorf("want %q, got %q", want, got)
+		}
+	}
+}
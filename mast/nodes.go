@@ -1,6 +1,9 @@
 package mast
 
 import (
+	"bytes"
+	"fmt"
+
 	"github.com/gomarkdown/markdown/ast"
 )
 
@@ -17,6 +20,27 @@ func MoveChildren(a, b ast.Node) {
 	}
 }
 
+// UniqueID returns id, or id with a "-N" suffix when id is already in ids, and records the returned ID
+// in ids. It's used to make the IDs of headings and generated sections unique.
+func UniqueID(ids map[string]int, id string) string {
+	for count, found := ids[id]; found; count, found = ids[id] {
+		tmp := fmt.Sprintf("%s-%d", id, count+1)
+
+		if _, tmpFound := ids[tmp]; !tmpFound {
+			ids[id] = count + 1
+			id = tmp
+		} else {
+			id = id + "-1"
+		}
+	}
+
+	if _, found := ids[id]; !found {
+		ids[id] = 0
+	}
+
+	return id
+}
+
 // Some attribute helper functions.
 
 func attributeFromNode(node ast.Node) *ast.Attribute {
@@ -52,13 +76,14 @@ func DeleteAttribute(node ast.Node, key string) {
 	case "id":
 		a.ID = nil
 	case "class":
-		// TODO
+		a.Classes = nil
 	default:
 		delete(a.Attrs, key)
 	}
 }
 
-// SetAttribute sets the attribute under key to value.
+// SetAttribute sets the attribute under key to value. For "class" value is a space separated list
+// of classes that replaces the current classes.
 func SetAttribute(node ast.Node, key string, value []byte) {
 	a := attributeFromNode(node)
 	if a == nil {
@@ -68,13 +93,14 @@ func SetAttribute(node ast.Node, key string, value []byte) {
 	case "id":
 		a.ID = value
 	case "class":
-		// TODO
+		a.Classes = bytes.Fields(value)
 	default:
 		a.Attrs[key] = value
 	}
 }
 
-// Attribute returns the attribute value under key.
+// Attribute returns the attribute value under key. For "class" the classes are returned as a space
+// separated list.
 func Attribute(node ast.Node, key string) []byte {
 	a := attributeFromNode(node)
 	if a == nil {
@@ -84,7 +110,10 @@ func Attribute(node ast.Node, key string) []byte {
 	case "id":
 		return a.ID
 	case "class":
-		// use AttributeClass.
+		if len(a.Classes) == 0 {
+			return nil
+		}
+		return bytes.Join(a.Classes, []byte(" "))
 	}
 
 	return a.Attrs[key]
@@ -104,19 +133,76 @@ func AttributeClass(node ast.Node, key string) bool {
 	return false
 }
 
+// Classes returns the classes set on node.
+func Classes(node ast.Node) []string {
+	a := attributeFromNode(node)
+	if a == nil {
+		return nil
+	}
+	classes := make([]string, len(a.Classes))
+	for i, c := range a.Classes {
+		classes[i] = string(c)
+	}
+	return classes
+}
+
+// AddClass adds class to node, if it isn't already set. An attribute is initialized if node
+// doesn't have one.
+func AddClass(node ast.Node, class string) {
+	if AttributeClass(node, class) {
+		return
+	}
+	AttributeInit(node)
+	a := attributeFromNode(node)
+	if a == nil {
+		return
+	}
+	a.Classes = append(a.Classes, []byte(class))
+}
+
+// RemoveClass removes class from node.
+func RemoveClass(node ast.Node, class string) {
+	a := attributeFromNode(node)
+	if a == nil {
+		return
+	}
+	classes := a.Classes[:0]
+	for _, c := range a.Classes {
+		if string(c) != class {
+			classes = append(classes, c)
+		}
+	}
+	a.Classes = classes
+	if len(a.Classes) == 0 {
+		a.Classes = nil
+	}
+}
+
+// ToggleClass removes class from node if it is set, otherwise it is added. It returns true
+// if class is set after the toggle.
+func ToggleClass(node ast.Node, class string) bool {
+	if AttributeClass(node, class) {
+		RemoveClass(node, class)
+		return false
+	}
+	AddClass(node, class)
+	return true
+}
+
 // AttributeFilter runs the attribute on node through filter and only allows elements for which filter returns true.
+// The filter is only called for keys that are set on node.
 func AttributeFilter(node ast.Node, filter func(key string) bool) {
 	a := attributeFromNode(node)
 	if a == nil {
 		return
 	}
-	if !filter("id") {
+	if a.ID != nil && !filter("id") {
 		a.ID = nil
 	}
-	if !filter("class") {
+	if len(a.Classes) > 0 && !filter("class") {
 		a.Classes = nil
 	}
-	for k, _ := range a.Attrs {
+	for k := range a.Attrs {
 		if !filter(k) {
 			delete(a.Attrs, k)
 		}
@@ -130,3 +216,7 @@ func AttributeFilter(node ast.Node, filter func(key string) bool) {
 // any other string means checking the individual attributes.
 // it returns true for elements that are allows, false otherwise.
 type FilterFunc func(s string) bool
+
+// ElementFilterFunc checks if key is an allowed attribute on the (XML) element. The key is
+// interpreted as in FilterFunc, element is empty when the element isn't known.
+type ElementFilterFunc func(element, key string) bool
@@ -0,0 +1,54 @@
+package mast
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gomarkdown/markdown/ast"
+)
+
+func TestClasses(t *testing.T) {
+	p := &ast.Paragraph{}
+
+	AddClass(p, "a")
+	AddClass(p, "b")
+	AddClass(p, "a")
+	if got, want := Classes(p), []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if got := string(Attribute(p, "class")); got != "a b" {
+		t.Errorf("want %q, got %q", "a b", got)
+	}
+
+	if ToggleClass(p, "a") {
+		t.Errorf("expected class %q to be removed", "a")
+	}
+	if !ToggleClass(p, "c") {
+		t.Errorf("expected class %q to be added", "c")
+	}
+	if got, want := Classes(p), []string{"b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+
+	SetAttribute(p, "class", []byte("x  y"))
+	if got, want := Classes(p), []string{"x", "y"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+
+	RemoveClass(p, "x")
+	DeleteAttribute(p, "class")
+	if got := Attribute(p, "class"); got != nil {
+		t.Errorf("want no classes, got %q", got)
+	}
+}
+
+func TestUniqueID(t *testing.T) {
+	ids := map[string]int{}
+	got := []string{}
+	for _, id := range []string{"a", "a", "a-1", "a"} {
+		got = append(got, UniqueID(ids, id))
+	}
+	if want := []string{"a", "a-1", "a-1-1", "a-2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,12 @@
+package mast
+
+import "github.com/gomarkdown/markdown/ast"
+
+// Raw is content that is passed through verbatim to the renderer for Format, i.e. "xml3", "xml2" or
+// "html". Other renderers drop it. The content is in Literal.
+type Raw struct {
+	ast.Leaf
+
+	Format string // Format the content is for.
+	Block  bool   // True for a raw block, false for an inline raw span.
+}
@@ -1,11 +1,14 @@
 // Package reference defines the elements of a <reference> block.
 package reference
 
+import "encoding/xml"
+
 // Author is the reference author.
 type Author struct {
-	Fullname string `xml:"fullname,attr"`
-	Initials string `xml:"initials,attr"`
-	Surname  string `xml:"surname,attr"`
+	Fullname     string `xml:"fullname,attr"`
+	Initials     string `xml:"initials,attr"`
+	Surname      string `xml:"surname,attr"`
+	Organization string `xml:"organization"`
 }
 
 // Date is the reference date.
@@ -15,11 +18,48 @@ type Date struct {
 	Day   string `xml:"day,attr,omitempty"`
 }
 
+// SeriesInfo is the reference <seriesInfo>, i.e. name "RFC" and value "2119".
+type SeriesInfo struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
 // Front the reference <front>.
 type Front struct {
-	Title  string `xml:"title"`
-	Author Author `xml:"author"`
-	Date   Date   `xml:"date"`
+	Title      string       `xml:"title"`
+	Author     Author       `xml:"author"` // The first author.
+	Date       Date         `xml:"date"`
+	SeriesInfo []SeriesInfo `xml:"seriesInfo"`
+	Authors    []Author     `xml:"-"` // All authors, including the first one.
+}
+
+// UnmarshalXML unmarshals the <front>, all authors are put in Authors.
+func (f *Front) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type front Front // without the UnmarshalXML method
+	var all struct {
+		front
+		Authors []Author `xml:"author"`
+	}
+	if err := d.DecodeElement(&all, &start); err != nil {
+		return err
+	}
+	*f = Front(all.front)
+	f.Authors = all.Authors
+	if len(f.Authors) > 0 {
+		f.Author = f.Authors[0]
+	}
+	return nil
+}
+
+// AllAuthors returns the authors of the reference: Authors, or Author when Authors is empty.
+func (f Front) AllAuthors() []Author {
+	if len(f.Authors) > 0 {
+		return f.Authors
+	}
+	if f.Author != (Author{}) {
+		return []Author{f.Author}
+	}
+	return nil
 }
 
 // Format is the reference <format>.
@@ -28,9 +68,11 @@ type Format struct {
 	Target string `xml:"target,attr"`
 }
 
-// Reference is the entire <reference> structure.
+// Reference is the entire <reference> structure. The <seriesInfo> may be in <front> or <reference>.
 type Reference struct {
-	Anchor string `xml:"anchor,attr"`
-	Front  Front  `xml:"front"`
-	Format Format `xml:"format"`
+	Anchor     string       `xml:"anchor,attr"`
+	Target     string       `xml:"target,attr,omitempty"`
+	Front      Front        `xml:"front"`
+	Format     Format       `xml:"format"`
+	SeriesInfo []SeriesInfo `xml:"seriesInfo"`
 }
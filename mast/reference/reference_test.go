@@ -0,0 +1,30 @@
+package reference
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+func TestFrontAuthors(t *testing.T) {
+	in := `<reference anchor="RFC8174"><front><title>Ambiguity</title>
+<author initials="B." surname="Leiba"/><author initials="J." surname="Doe"/>
+<date year="2017" month="May"/></front></reference>`
+	var ref Reference
+	if err := xml.Unmarshal([]byte(in), &ref); err != nil {
+		t.Fatal(err)
+	}
+	if ref.Front.Title != "Ambiguity" || ref.Front.Date.Year != "2017" {
+		t.Errorf("want title and date, got %+v", ref.Front)
+	}
+	if len(ref.Front.Authors) != 2 || ref.Front.Authors[1].Surname != "Doe" {
+		t.Errorf("want 2 authors, got %+v", ref.Front.Authors)
+	}
+	if ref.Front.Author.Surname != "Leiba" {
+		t.Errorf("want first author %q, got %q", "Leiba", ref.Front.Author.Surname)
+	}
+
+	f := Front{Author: Author{Surname: "Bradner"}}
+	if a := f.AllAuthors(); len(a) != 1 || a[0].Surname != "Bradner" {
+		t.Errorf("want Author as the only author, got %+v", a)
+	}
+}
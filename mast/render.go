@@ -0,0 +1,66 @@
+package mast
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"reflect"
+
+	"github.com/gomarkdown/markdown/ast"
+)
+
+// RenderFunc renders a single node, it is called when entering and when exiting the node.
+type RenderFunc func(w io.Writer, node ast.Node, entering bool) ast.WalkStatus
+
+// Renderers maps node types to the function that renders them. It allows renderers to be
+// extended with new node types, or to override the rendering of existing ones.
+type Renderers map[reflect.Type]RenderFunc
+
+// Register registers fn as the function that renders nodes of the same type as node.
+func (r Renderers) Register(node ast.Node, fn RenderFunc) {
+	r[reflect.TypeOf(node)] = fn
+}
+
+// Lookup returns the function registered for the type of node, or nil if there is none.
+func (r Renderers) Lookup(node ast.Node) RenderFunc {
+	if r == nil {
+		return nil
+	}
+	return r[reflect.TypeOf(node)]
+}
+
+// UnknownNode tells a renderer what to do with node types it doesn't know how to render.
+type UnknownNode int
+
+const (
+	UnknownChildren UnknownNode = iota // Warn and render the children of the node.
+	UnknownSkip                        // Warn and skip the node and its children.
+	UnknownError                       // Stop rendering, the renderer returns an error.
+)
+
+// UnknownNodes maps the names of the UnknownNode values, as used on the command line, to them.
+var UnknownNodes = map[string]UnknownNode{
+	"children": UnknownChildren,
+	"skip":     UnknownSkip,
+	"error":    UnknownError,
+}
+
+// Unknown handles the rendering of node, which is of a type the renderer doesn't know. A warning is
+// logged when entering node. If u is UnknownError, an error is returned and rendering should
+// be stopped.
+func (u UnknownNode) Unknown(node ast.Node, entering bool) (ast.WalkStatus, error) {
+	switch u {
+	case UnknownSkip:
+		if entering {
+			log.Printf("Unknown node %T, skipping", node)
+			return ast.SkipChildren, nil
+		}
+	case UnknownError:
+		return ast.Terminate, fmt.Errorf("unknown node %T", node)
+	default:
+		if entering {
+			log.Printf("Unknown node %T, rendering children", node)
+		}
+	}
+	return ast.GoToNext, nil
+}
@@ -1,8 +1,6 @@
 package mast
 
 import (
-	"time"
-
 	"github.com/gomarkdown/markdown/ast"
 )
 
@@ -35,8 +33,9 @@ type TitleData struct {
 	Obsoletes      []int
 	Updates        []int
 	SubmissionType string // IETF, IAB, IRTF or independent
+	Language       string `toml:"lang"` // Language of the document, i.e. "en", selects the generated labels
 
-	Date      time.Time
+	Date      Date // possibly partial, see Date
 	Area      string
 	Workgroup string
 	Keyword   []string
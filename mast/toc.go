@@ -0,0 +1,8 @@
+package mast
+
+import "github.com/gomarkdown/markdown/ast"
+
+// TableOfContents marks the place in the document where the table of contents should be rendered.
+type TableOfContents struct {
+	ast.Leaf
+}
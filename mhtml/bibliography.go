@@ -0,0 +1,311 @@
+package mhtml
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/mmarkdown/mmark/mast"
+	"github.com/mmarkdown/mmark/mast/reference"
+)
+
+// CitationStyle tells how citations and bibliography entries are labeled.
+type CitationStyle int
+
+const (
+	CitationAnchor     CitationStyle = iota // The anchor of the reference, i.e. [RFC2119].
+	CitationNumeric                         // The number of the reference in the bibliography, i.e. [1].
+	CitationAuthorYear                      // The first author and year, i.e. [Bradner, 1997].
+)
+
+// CitationStyles maps the names of the citation styles to their values.
+var CitationStyles = map[string]CitationStyle{
+	"anchor":      CitationAnchor,
+	"numeric":     CitationNumeric,
+	"author-year": CitationAuthorYear,
+}
+
+// Bibliography renders the normative and informative bibliography and the citations pointing into
+// them. Entries are formatted like xml2rfc does, labels and citations use Style.
+type Bibliography struct {
+	Style CitationStyle
+	Href  func(id string) string // Href returns the link to a bibliography entry, defaults to "#id".
+
+	numbers    map[string]int    // lowercased anchor -> number in the bibliography
+	authorYear map[string]string // lowercased anchor -> author-year label
+}
+
+// NewBibliography returns a Bibliography for the bibliography items in doc.
+func NewBibliography(doc ast.Node, style CitationStyle) *Bibliography {
+	b := &Bibliography{Style: style, numbers: map[string]int{}, authorYear: map[string]string{}}
+	seen := map[string]int{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		item, ok := node.(*mast.BibliographyItem)
+		if !ok || !entering {
+			return ast.GoToNext
+		}
+		anchor := strings.ToLower(string(item.Anchor))
+		if _, ok := b.numbers[anchor]; ok {
+			return ast.GoToNext
+		}
+		b.numbers[anchor] = len(b.numbers) + 1
+
+		label := authorYear(item.Reference)
+		if label == "" {
+			return ast.GoToNext
+		}
+		seen[label]++
+		if n := seen[label]; n > 1 {
+			// Disambiguate with a letter after the year, the first one gets its "a" below.
+			label += string(rune('a' + n - 1))
+		}
+		b.authorYear[anchor] = label
+		return ast.GoToNext
+	})
+	for anchor, label := range b.authorYear {
+		if seen[label] > 1 {
+			b.authorYear[anchor] = label + "a"
+		}
+	}
+	return b
+}
+
+// Label returns the label for anchor in the citation style of b, without the brackets. If no label
+// can be made the anchor is returned.
+func (b *Bibliography) Label(anchor string) string {
+	switch b.Style {
+	case CitationNumeric:
+		if n, ok := b.numbers[strings.ToLower(anchor)]; ok {
+			return strconv.Itoa(n)
+		}
+	case CitationAuthorYear:
+		if l, ok := b.authorYear[strings.ToLower(anchor)]; ok {
+			return l
+		}
+	}
+	return anchor
+}
+
+// RenderNode renders the mast.Bibliography, mast.BibliographyItem and ast.Citation nodes.
+func (b *Bibliography) RenderNode(w io.Writer, node ast.Node, entering bool) ast.WalkStatus {
+	switch node := node.(type) {
+	case *mast.Bibliography:
+		if !entering {
+			io.WriteString(w, "</dl>\n</div>\n")
+			return ast.GoToNext
+		}
+		switch node.Type {
+		case ast.CitationTypeInformative:
+			io.WriteString(w, "<h1 id=\"informative-references\">")
+			html.EscapeHTML(w, []byte(Labels.InformativeReferences))
+		default:
+			io.WriteString(w, "<h1 id=\"normative-references\">")
+			html.EscapeHTML(w, []byte(Labels.NormativeReferences))
+		}
+		io.WriteString(w, "</h1>\n<div class=\"bibliography\">\n<dl>\n")
+	case *mast.BibliographyItem:
+		if entering {
+			b.item(w, node)
+		}
+	case *ast.Citation:
+		if entering {
+			b.citation(w, node)
+		}
+	}
+	return ast.GoToNext
+}
+
+func (b *Bibliography) citation(w io.Writer, node *ast.Citation) {
+	for i, c := range node.Destination {
+		class := "none"
+		switch node.Type[i] {
+		case ast.CitationTypeNormative:
+			class = "normative"
+		case ast.CitationTypeInformative:
+			class = "informative"
+		case ast.CitationTypeSuppressed:
+			class = "suppressed"
+		}
+		loc := mast.CitationLocator(node.Suffix[i])
+		if loc != nil && loc.Format == "of" {
+			locator(w, string(c), loc)
+			io.WriteString(w, " "+Labels.Of+" ")
+		}
+		if loc != nil && loc.Format == "bare" {
+			locator(w, string(c), loc)
+			continue
+		}
+		io.WriteString(w, `<cite class="`+class+`"><a href="`+href(b.Href, string(c))+`">[`)
+		html.EscapeHTML(w, []byte(b.Label(string(c))))
+		io.WriteString(w, "]</a></cite>")
+		if loc == nil {
+			continue
+		}
+		switch loc.Format {
+		case "comma":
+			io.WriteString(w, ", ")
+			locator(w, string(c), loc)
+		case "parens":
+			io.WriteString(w, " (")
+			locator(w, string(c), loc)
+			io.WriteString(w, ")")
+		}
+	}
+}
+
+// locator outputs the section of the citation of anchor, linked to that section when the document
+// is an RFC or I-D.
+func locator(w io.Writer, anchor string, loc *mast.Locator) {
+	text := loc.Text(Labels.Section, Labels.Appendix)
+	url := sectionURL(anchor, loc)
+	if url == "" {
+		html.EscapeHTML(w, []byte(text))
+		return
+	}
+	io.WriteString(w, `<a class="section" href="`+url+`">`)
+	html.EscapeHTML(w, []byte(text))
+	io.WriteString(w, "</a>")
+}
+
+// sectionURL returns the URL of the section of the RFC or I-D anchor, or the empty string for other
+// documents.
+func sectionURL(anchor string, loc *mast.Locator) string {
+	switch {
+	case len(anchor) > 3 && strings.EqualFold(anchor[:3], "RFC") && isDigits(anchor[3:]):
+		return "https://www.rfc-editor.org/rfc/rfc" + strings.TrimLeft(anchor[3:], "0") + "#" + loc.Fragment()
+	case strings.HasPrefix(anchor, "I-D."):
+		draft := anchor[len("I-D."):]
+		if i := strings.Index(draft, "#"); i > 0 {
+			draft = draft[:i] + "-" + draft[i+1:]
+		}
+		return "https://datatracker.ietf.org/doc/html/draft-" + draft + "#" + loc.Fragment()
+	}
+	return ""
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// item outputs a bibliography entry, formatted as: authors, "title", series info, DOI, date, <target>.
+func (b *Bibliography) item(w io.Writer, item *mast.BibliographyItem) {
+	anchor := string(item.Anchor)
+	io.WriteString(w, `<dt class="bibliography-cite" id="`+anchor+`">[`)
+	html.EscapeHTML(w, []byte(b.Label(anchor)))
+	io.WriteString(w, "]</dt>\n<dd>")
+
+	ref := item.Reference
+	sep := ""
+	part := func(open, text, close string) {
+		io.WriteString(w, sep+open)
+		html.EscapeHTML(w, []byte(text))
+		io.WriteString(w, close)
+		sep = ", "
+	}
+	if a := authors(ref.Front.AllAuthors()); a != "" {
+		part(`<span class="bibliography-author">`, a, "</span>")
+	}
+	if ref.Front.Title != "" {
+		part(`"<span class="bibliography-title">`, ref.Front.Title, `</span>"`)
+	}
+	series, doi := seriesInfo(ref)
+	if series != "" {
+		part(`<span class="bibliography-series">`, series, "</span>")
+	}
+	if doi != "" {
+		part(`<span class="bibliography-doi">`, "DOI "+doi, "</span>")
+	}
+	if d := date(ref.Front.Date); d != "" {
+		part(`<time class="bibliography-date">`, d, "</time>")
+	}
+	target := ref.Target
+	if target == "" {
+		target = ref.Format.Target
+	}
+	if target != "" {
+		io.WriteString(w, sep+`&lt;<a class="bibliography-target" href="`)
+		html.EscapeHTML(w, []byte(target))
+		sep = ""
+		part(`">`, target, "</a>&gt;")
+	}
+	if sep != "" {
+		io.WriteString(w, ".")
+	}
+	io.WriteString(w, "</dd>\n")
+}
+
+// authors returns the authors like xml2rfc: "Bradner, S.", "Bradner, S. and J. Doe" or "Bradner, S.,
+// Doe, J., and A. Smith".
+func authors(authors []reference.Author) string {
+	names := []string{}
+	for i, a := range authors {
+		switch {
+		case a.Surname != "" && i == 0:
+			names = append(names, strings.TrimSuffix(a.Surname+", "+a.Initials, ", "))
+		case a.Surname != "":
+			names = append(names, strings.TrimSpace(a.Initials+" "+a.Surname))
+		case a.Fullname != "":
+			names = append(names, a.Fullname)
+		case a.Organization != "":
+			names = append(names, a.Organization)
+		}
+	}
+	switch len(names) {
+	case 0:
+		return ""
+	case 1:
+		return names[0]
+	case 2:
+		return names[0] + " " + Labels.And + " " + names[1]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + ", " + Labels.And + " " + names[len(names)-1]
+}
+
+// seriesInfo returns the series information, i.e. "BCP 14, RFC 2119", and the DOI of ref.
+func seriesInfo(ref reference.Reference) (series, doi string) {
+	s := []string{}
+	for _, si := range append(ref.Front.SeriesInfo, ref.SeriesInfo...) {
+		if si.Name == "DOI" {
+			doi = si.Value
+			continue
+		}
+		s = append(s, strings.TrimSpace(si.Name+" "+si.Value))
+	}
+	return strings.Join(s, ", "), doi
+}
+
+// date returns the date as "March 1997".
+func date(d reference.Date) string {
+	month := d.Month
+	if m, err := strconv.Atoi(month); err == nil && m >= 1 && m <= 12 {
+		month = Labels.Month(m)
+	}
+	return strings.TrimSpace(month + " " + d.Year)
+}
+
+// authorYear returns the author-year label for ref, i.e. "Bradner, 1997" or "Bradner et al., 2018".
+// The empty string is returned when ref doesn't have an author with a surname.
+func authorYear(ref reference.Reference) string {
+	a := ref.Front.AllAuthors()
+	if len(a) == 0 || a[0].Surname == "" {
+		return ""
+	}
+	label := a[0].Surname
+	switch {
+	case len(a) == 2 && a[1].Surname != "":
+		label += " " + Labels.And + " " + a[1].Surname
+	case len(a) > 2:
+		label += " " + Labels.EtAl
+	}
+	if ref.Front.Date.Year != "" {
+		label += ", " + ref.Front.Date.Year
+	}
+	return label
+}
@@ -0,0 +1,129 @@
+package mhtml
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/lang"
+	"github.com/mmarkdown/mmark/mast"
+	"github.com/mmarkdown/mmark/mast/reference"
+)
+
+func bibliographyDoc() ast.Node {
+	doc := &ast.Document{}
+	norm := &mast.Bibliography{Type: ast.CitationTypeNormative}
+	ast.AppendChild(norm, &mast.BibliographyItem{
+		Anchor: []byte("RFC2119"),
+		Reference: reference.Reference{
+			Target: "https://www.rfc-editor.org/info/rfc2119",
+			Front: reference.Front{
+				Title:   "Key words for use in RFCs to Indicate Requirement Levels",
+				Authors: []reference.Author{{Initials: "S.", Surname: "Bradner"}},
+				Date:    reference.Date{Year: "1997", Month: "3"},
+			},
+			SeriesInfo: []reference.SeriesInfo{
+				{Name: "BCP", Value: "14"}, {Name: "RFC", Value: "2119"}, {Name: "DOI", Value: "10.17487/RFC2119"},
+			},
+		},
+	})
+	inform := &mast.Bibliography{Type: ast.CitationTypeInformative}
+	ast.AppendChild(inform, &mast.BibliographyItem{
+		Anchor: []byte("RFC8174"),
+		Reference: reference.Reference{
+			Front: reference.Front{
+				Authors: []reference.Author{{Initials: "B.", Surname: "Leiba"}, {Initials: "J.", Surname: "Doe"}},
+				Date:    reference.Date{Year: "2017"},
+			},
+		},
+	})
+	ast.AppendChild(inform, &mast.BibliographyItem{Anchor: []byte("I-D.foo")})
+	ast.AppendChild(doc, norm)
+	ast.AppendChild(doc, inform)
+	return doc
+}
+
+func TestBibliographyLabel(t *testing.T) {
+	b := NewBibliography(bibliographyDoc(), CitationAnchor)
+	tests := []struct {
+		style  CitationStyle
+		anchor string
+		want   string
+	}{
+		{CitationAnchor, "RFC2119", "RFC2119"},
+		{CitationNumeric, "RFC2119", "1"},
+		{CitationNumeric, "rfc8174", "2"},
+		{CitationNumeric, "unknown", "unknown"},
+		{CitationAuthorYear, "RFC2119", "Bradner, 1997"},
+		{CitationAuthorYear, "RFC8174", "Leiba and Doe, 2017"},
+		{CitationAuthorYear, "I-D.foo", "I-D.foo"},
+	}
+	for i, tc := range tests {
+		b.Style = tc.style
+		if got := b.Label(tc.anchor); got != tc.want {
+			t.Errorf("test %d, want %q, got %q", i, tc.want, got)
+		}
+	}
+}
+
+func TestBibliographyItem(t *testing.T) {
+	doc := bibliographyDoc()
+	b := NewBibliography(doc, CitationNumeric)
+	buf := &bytes.Buffer{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		return b.RenderNode(buf, node, entering)
+	})
+	out := buf.String()
+
+	for _, want := range []string{
+		`<h1 id="normative-references">Normative References</h1>`,
+		`<h1 id="informative-references">Informative References</h1>`,
+		`<dt class="bibliography-cite" id="RFC2119">[1]</dt>`,
+		`<span class="bibliography-author">Bradner, S.</span>, "<span class="bibliography-title">Key words for use in RFCs to Indicate Requirement Levels</span>", ` +
+			`<span class="bibliography-series">BCP 14, RFC 2119</span>, <span class="bibliography-doi">DOI 10.17487/RFC2119</span>, ` +
+			`<time class="bibliography-date">March 1997</time>, &lt;<a class="bibliography-target" href="https://www.rfc-editor.org/info/rfc2119">https://www.rfc-editor.org/info/rfc2119</a>&gt;.</dd>`,
+		`<span class="bibliography-author">Leiba, B. and J. Doe</span>, <time class="bibliography-date">2017</time>.</dd>`,
+		`<dt class="bibliography-cite" id="I-D.foo">[3]</dt>` + "\n<dd></dd>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("want %q in output, got %s", want, out)
+		}
+	}
+}
+
+func TestBibliographyCitationLocator(t *testing.T) {
+	b := NewBibliography(bibliographyDoc(), CitationAnchor)
+	cite := &ast.Citation{
+		Destination: [][]byte{[]byte("RFC8446"), []byte("I-D.ietf-foo#03")},
+		Type:        []ast.CitationTypes{ast.CitationTypeInformative, ast.CitationTypeNormative},
+		Suffix:      [][]byte{[]byte("section 4.2"), []byte("appendix B, parens")},
+	}
+	buf := &bytes.Buffer{}
+	b.RenderNode(buf, cite, true)
+	for _, want := range []string{
+		`<a class="section" href="https://www.rfc-editor.org/rfc/rfc8446#section-4.2">Section 4.2</a> of <cite`,
+		` (<a class="section" href="https://datatracker.ietf.org/doc/html/draft-ietf-foo-03#appendix-B">Appendix B</a>)`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("want %q in %q", want, buf.String())
+		}
+	}
+}
+
+func TestBibliographyCitationLocatorLang(t *testing.T) {
+	defer func(l lang.Lang) { Labels = l }(Labels)
+	Labels = lang.New("nl")
+
+	b := NewBibliography(bibliographyDoc(), CitationAnchor)
+	cite := &ast.Citation{
+		Destination: [][]byte{[]byte("RFC8446")},
+		Type:        []ast.CitationTypes{ast.CitationTypeInformative},
+		Suffix:      [][]byte{[]byte("section 4.2")},
+	}
+	buf := &bytes.Buffer{}
+	b.RenderNode(buf, cite, true)
+	if want := "</a> van <cite"; !strings.Contains(buf.String(), want) {
+		t.Errorf("want %q in %q", want, buf.String())
+	}
+}
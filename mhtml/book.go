@@ -0,0 +1,391 @@
+package mhtml
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/mmarkdown/mmark/mast"
+)
+
+// Split tells where a book is split into pages.
+type Split int
+
+const (
+	SplitChapter Split = iota // Start a new page at each level 1 heading and document division.
+	SplitMatter               // Start a new page at each document division.
+)
+
+// Book renders a document as multiple HTML pages. The first page, index.html, holds everything up to
+// the main matter and the table of contents, each following page is named after the ID of its
+// first heading. All pages get previous/next navigation and links between pages are rewritten to
+// point into the correct page. Footnotes are rendered on the last page, the links to and from them are
+// rewritten as well.
+type Book struct {
+	TOC          TOC           // Options for the table of contents, Href is set by the book.
+	Bibliography *Bibliography // Bibliography and citations, Href is set by the book.
+
+	doc       ast.Node
+	pages     []*page
+	page      map[ast.Node]int  // top level node -> page number
+	ids       map[string]string // id -> page name
+	notes     map[string]string // footnote -> name of the page it is first referenced on
+	numbering *Numbering
+}
+
+type page struct {
+	name  string
+	title string
+}
+
+// NewBook splits doc into pages. If doc doesn't contain a table of contents, one is added at the end
+// of the first page.
+func NewBook(doc ast.Node, split Split) *Book {
+	b := &Book{
+		TOC:   TOC{Depth: TOCDepth},
+		doc:   doc,
+		page:  map[ast.Node]int{},
+		ids:   map[string]string{},
+		notes: map[string]string{},
+	}
+
+	title := Labels.Start
+	if t := findTitle(doc); t != nil && t.TitleData != nil && t.TitleData.Title != "" {
+		title = t.TitleData.Title
+	}
+	b.pages = append(b.pages, &page{name: "index.html", title: title})
+
+	var (
+		last    ast.Node // last node of the first page
+		front   = false
+		divided = false // a new page was started by a document division, and has no headings yet
+	)
+	for _, child := range doc.GetChildren() {
+		newPage := false
+		switch node := child.(type) {
+		case *ast.DocumentMatter:
+			front = node.Matter == ast.DocumentMatterFront
+			if !front {
+				newPage = true
+			}
+		case *ast.Heading:
+			if split == SplitChapter && node.Level == 1 && !node.IsTitleblock && !front && !divided &&
+				!(node.IsSpecial && isAbstract(node.Literal)) {
+				newPage = true
+			}
+		case *mast.DocumentIndex:
+			newPage = true
+		}
+
+		if newPage {
+			b.pages = append(b.pages, &page{name: "page-" + strconv.Itoa(len(b.pages)) + ".html"})
+		}
+		p := len(b.pages) - 1
+		b.page[child] = p
+		if p == 0 {
+			last = child
+		}
+
+		switch node := child.(type) {
+		case *ast.DocumentMatter:
+			divided = newPage
+		case *ast.Heading:
+			if b.pages[p].title == "" {
+				b.pages[p].title = headingText(node)
+				if name := pageName(headingID(node)); name != "" && !b.used(name) {
+					b.pages[p].name = name
+				}
+			}
+			divided = false
+		case *mast.DocumentIndex:
+			b.pages[p].title = Labels.Index
+			if !b.used("document-index.html") {
+				b.pages[p].name = "document-index.html"
+			}
+		default:
+			divided = false
+		}
+	}
+	for _, p := range b.pages {
+		if p.title == "" {
+			p.title = title
+		}
+	}
+
+	if !hasTOC(doc) {
+		toc := &mast.TableOfContents{}
+		insertAfter(doc, last, toc)
+		b.page[toc] = 0
+	}
+
+	for _, child := range doc.GetChildren() {
+		name := b.pages[b.page[child]].name
+		ast.WalkFunc(child, func(node ast.Node, entering bool) ast.WalkStatus {
+			if !entering {
+				return ast.GoToNext
+			}
+			for _, id := range nodeIDs(node) {
+				if _, ok := b.ids[id]; !ok {
+					b.ids[id] = name
+				}
+			}
+			if link, ok := node.(*ast.Link); ok && link.NoteID != 0 {
+				if _, ok := b.notes[footnoteSlug(link.Destination)]; !ok {
+					b.notes[footnoteSlug(link.Destination)] = name
+				}
+			}
+			return ast.GoToNext
+		})
+	}
+
+	// Rewrite the plain links, the other links are rewritten when rendering.
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if link, ok := node.(*ast.Link); ok && entering && len(link.Destination) > 1 && link.Destination[0] == '#' {
+			link.Destination = []byte(b.Href(string(link.Destination[1:])))
+		}
+		return ast.GoToNext
+	})
+
+	b.numbering = NewNumbering(doc)
+	b.numbering.Href = b.Href
+	b.TOC.Href = b.Href
+	b.Bibliography = NewBibliography(doc, CitationAnchor)
+	b.Bibliography.Href = b.Href
+	return b
+}
+
+// pageName returns the file name of the page for the heading id, or the empty string if id can't be
+// used as a file name: it's empty, holds a path separator or a "..", or starts with a dot.
+func pageName(id string) string {
+	if id == "" || strings.ContainsAny(id, `/\:`) || strings.Contains(id, "..") || strings.HasPrefix(id, ".") {
+		return ""
+	}
+	return id + ".html"
+}
+
+// Pages returns the names of the pages of the book, in order.
+func (b *Book) Pages() []string {
+	names := make([]string, len(b.pages))
+	for i, p := range b.pages {
+		names[i] = p.name
+	}
+	return names
+}
+
+// Href returns the link to id, this includes the name of the page id is on. An empty id links to the
+// first page.
+func (b *Book) Href(id string) string {
+	if id == "" {
+		return b.pages[0].name
+	}
+	return b.ids[id] + "#" + id
+}
+
+// Render renders all pages with opts and calls write for each of them. The RenderNodeHook of opts is
+// used to render the nodes not handled by the book.
+func (b *Book) Render(opts html.RendererOptions, write func(name string, data []byte) error) error {
+	for i, p := range b.pages {
+		o := opts
+		o.RenderNodeHook = b.hook(i, opts)
+		if o.Title != "" && i > 0 {
+			o.Title = p.title + " - " + o.Title
+		}
+		data := markdown.Render(b.doc, html.NewRenderer(o))
+		if err := write(p.name, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hook returns the RenderNodeFunc for page p, nodes not on p are skipped. The RenderNodeHook of opts
+// renders the nodes not handled by the book.
+func (b *Book) hook(p int, opts html.RendererOptions) html.RenderNodeFunc {
+	base := opts.RenderNodeHook
+	if base == nil {
+		base = RenderHook
+	}
+	last := b.pages[len(b.pages)-1].name
+	returnLink := opts.FootnoteReturnLinkContents
+	if returnLink == "" {
+		returnLink = "<sup>[return]</sup>" // the default of the HTML renderer
+	}
+	matter := false // a document matter section is open
+	return func(w io.Writer, node ast.Node, entering bool) (ast.WalkStatus, bool) {
+		if node.GetParent() == b.doc {
+			n, ok := b.page[node]
+			if _, footnotes := node.(*ast.Footnotes); footnotes {
+				n = len(b.pages) - 1
+			}
+			if ok && n != p {
+				return ast.SkipChildren, true
+			}
+		}
+
+		switch node := node.(type) {
+		case *ast.Document:
+			if entering {
+				base(w, node, entering)
+				b.nav(w, p, "book-nav-top")
+				return ast.GoToNext, true
+			}
+			if p == len(b.pages)-1 {
+				base(w, node, entering)
+			}
+			if matter {
+				io.WriteString(w, "</section>\n")
+			}
+			b.nav(w, p, "book-nav-bottom")
+			return ast.GoToNext, true
+		case *ast.DocumentMatter:
+			// The sections are written here, and not by the HTML renderer, so the bottom navigation
+			// can be put after the last one.
+			if !entering {
+				return ast.GoToNext, true
+			}
+			if matter {
+				io.WriteString(w, "</section>\n")
+			}
+			switch node.Matter {
+			case ast.DocumentMatterFront:
+				io.WriteString(w, `<section data-matter="front">`)
+			case ast.DocumentMatterMain:
+				io.WriteString(w, `<section data-matter="main">`)
+			case ast.DocumentMatterBack:
+				io.WriteString(w, `<section data-matter="back">`)
+			}
+			matter = true
+			return ast.GoToNext, true
+		case *ast.Link:
+			// Footnotes are on the last page.
+			if node.NoteID == 0 {
+				break
+			}
+			if entering {
+				slug := opts.FootnoteAnchorPrefix + footnoteSlug(node.Destination)
+				io.WriteString(w, `<sup class="footnote-ref" id="fnref:`+slug+`"><a href="`+last+`#fn:`+slug+`">`)
+				io.WriteString(w, strconv.Itoa(node.NoteID)+"</a></sup>")
+			}
+			return ast.GoToNext, true
+		case *ast.ListItem:
+			// The link back from a footnote goes to the page it is referenced on.
+			if node.RefLink == nil || entering || opts.Flags&html.FootnoteReturnLinks == 0 {
+				break
+			}
+			slug := footnoteSlug(node.RefLink)
+			io.WriteString(w, ` <a class="footnote-return" href="`+b.notes[slug]+`#fnref:`+opts.FootnoteAnchorPrefix+slug+`">`)
+			io.WriteString(w, returnLink+"</a></li>\n")
+			return ast.GoToNext, true
+		case *mast.TableOfContents:
+			return b.TOC.RenderNode(w, node, entering), true
+		case *ast.CrossReference:
+			return b.numbering.RenderNode(w, node, entering)
+		case *mast.Bibliography, *mast.BibliographyItem, *ast.Citation:
+			return b.Bibliography.RenderNode(w, node, entering), true
+		case *mast.IndexLink:
+			if !entering {
+				io.WriteString(w, "</a>")
+				return ast.GoToNext, true
+			}
+			io.WriteString(w, ` <a class="index-return" href="`+b.Href(string(node.Destination))+`">`)
+			io.WriteString(w, IndexReturnLinkContents)
+			return ast.GoToNext, true
+		}
+		return base(w, node, entering)
+	}
+}
+
+// footnoteSlug returns the anchor the HTML renderer uses for the footnote ref: runs of
+// non-alphanumeric characters become a single dash, leading and trailing dashes are removed.
+func footnoteSlug(ref []byte) string {
+	out := []byte{}
+	sym := false
+	for _, c := range ref {
+		if c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' {
+			out = append(out, c)
+			sym = false
+			continue
+		}
+		if !sym {
+			out = append(out, '-')
+			sym = true
+		}
+	}
+	return strings.Trim(string(out), "-")
+}
+
+// nav outputs the navigation between the pages.
+func (b *Book) nav(w io.Writer, p int, class string) {
+	io.WriteString(w, "\n<nav class=\"book-nav "+class+"\">\n")
+	if p > 0 {
+		b.navLink(w, "prev", b.pages[p-1])
+	}
+	io.WriteString(w, `<a class="contents" href="`+b.Href("table-of-contents")+`">`)
+	html.EscapeHTML(w, []byte(Labels.Contents))
+	io.WriteString(w, "</a>\n")
+	if p < len(b.pages)-1 {
+		b.navLink(w, "next", b.pages[p+1])
+	}
+	io.WriteString(w, "</nav>\n")
+}
+
+func (b *Book) navLink(w io.Writer, rel string, p *page) {
+	io.WriteString(w, `<a class="`+rel+`" rel="`+rel+`" href="`+p.name+`">`)
+	html.EscapeHTML(w, []byte(p.title))
+	io.WriteString(w, "</a>\n")
+}
+
+// used returns true if a page is already named name.
+func (b *Book) used(name string) bool {
+	for _, p := range b.pages {
+		if p.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeIDs returns the IDs node can be linked to with.
+func nodeIDs(node ast.Node) []string {
+	ids := []string{}
+	if id := mast.Attribute(node, "id"); id != nil {
+		ids = append(ids, string(id))
+	}
+	switch node := node.(type) {
+	case *ast.Heading:
+		if node.HeadingID != "" {
+			ids = append(ids, node.HeadingID)
+		}
+	case *ast.Index:
+		ids = append(ids, node.ID)
+	case *mast.BibliographyItem:
+		ids = append(ids, string(node.Anchor))
+	case *mast.Bibliography:
+		if node.Type == ast.CitationTypeInformative {
+			ids = append(ids, "informative-references")
+		} else {
+			ids = append(ids, "normative-references")
+		}
+	case *mast.DocumentIndex:
+		ids = append(ids, "index-section")
+	case *mast.TableOfContents:
+		ids = append(ids, "table-of-contents")
+	}
+	return ids
+}
+
+// hasTOC returns true if doc contains a mast.TableOfContents node.
+func hasTOC(doc ast.Node) bool {
+	found := false
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if _, ok := node.(*mast.TableOfContents); ok {
+			found = true
+			return ast.Terminate
+		}
+		return ast.GoToNext
+	})
+	return found
+}
@@ -0,0 +1,104 @@
+package mhtml
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+func TestBook(t *testing.T) {
+	in := ".# Abstract\n\n{mainmatter}\n\n# Intro\n\n# Two\n\n## Deep\n\n{backmatter}\n\n# App\n"
+	p := parser.NewWithExtensions(parser.CommonExtensions | parser.AutoHeadingIDs | parser.Mmark)
+	doc := markdown.Parse([]byte(in), p)
+
+	book := NewBook(doc, SplitChapter)
+	got := book.Pages()
+	want := []string{"index.html", "intro.html", "two.html", "app.html"}
+	if len(got) != len(want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("want %q, got %q", want[i], got[i])
+		}
+	}
+
+	hrefs := map[string]string{
+		"abstract":          "index.html#abstract",
+		"table-of-contents": "index.html#table-of-contents",
+		"deep":              "two.html#deep",
+		"app":               "app.html#app",
+	}
+	for id, want := range hrefs {
+		if got := book.Href(id); got != want {
+			t.Errorf("want %q, got %q, for %s", want, got, id)
+		}
+	}
+
+	book = NewBook(markdown.Parse([]byte(in), parser.NewWithExtensions(parser.CommonExtensions|parser.AutoHeadingIDs|parser.Mmark)), SplitMatter)
+	if got := len(book.Pages()); got != 3 {
+		t.Errorf("want %d pages, got %d", 3, got)
+	}
+}
+
+func TestBookPageNames(t *testing.T) {
+	in := "{mainmatter}\n\n# One {#../../escaped}\n\n# Two {#sub/dir}\n\n# Three {#.hidden}\n\n# Four {#four}\n"
+	p := parser.NewWithExtensions(parser.CommonExtensions | parser.Mmark)
+	doc := markdown.Parse([]byte(in), p)
+
+	got := NewBook(doc, SplitChapter).Pages()
+	want := []string{"index.html", "page-1.html", "page-2.html", "page-3.html", "four.html"}
+	if len(got) != len(want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("want %q, got %q", want[i], got[i])
+		}
+	}
+}
+
+func TestBookRender(t *testing.T) {
+	in := "{mainmatter}\n\n# One\n\nText[^a].\n\n# Two\n\nMore.\n\n[^a]: A note.\n"
+	p := parser.NewWithExtensions(parser.CommonExtensions | parser.AutoHeadingIDs | parser.Footnotes | parser.Mmark)
+	doc := markdown.Parse([]byte(in), p)
+
+	pages := map[string]string{}
+	opts := html.RendererOptions{Flags: html.CommonFlags | html.FootnoteReturnLinks}
+	err := NewBook(doc, SplitChapter).Render(opts, func(name string, data []byte) error {
+		pages[name] = string(data)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		page, want string
+	}{
+		{"index.html", `<a class="next" rel="next" href="one.html">One</a>`},
+		{"one.html", `<a href="two.html#fn:a">1</a>`},
+		{"one.html", "</section>\n\n<nav class=\"book-nav book-nav-bottom\">"},
+		{"two.html", `<a class="footnote-return" href="one.html#fnref:a">`},
+	}
+	for _, tc := range tests {
+		if !strings.Contains(pages[tc.page], tc.want) {
+			t.Errorf("want %q in %s, got %s", tc.want, tc.page, pages[tc.page])
+		}
+	}
+	if got := strings.Count(pages["one.html"], "</section>"); got != 1 {
+		t.Errorf("want 1 section in one.html, got %d", got)
+	}
+}
+
+func TestFootnoteSlug(t *testing.T) {
+	tests := map[string]string{"a": "a", "my note": "my-note", "-x--y!": "x-y"}
+	for in, want := range tests {
+		if got := footnoteSlug([]byte(in)); got != want {
+			t.Errorf("want %q, got %q, for %q", want, got, in)
+		}
+	}
+}
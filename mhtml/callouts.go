@@ -0,0 +1,135 @@
+package mhtml
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+// Callouts links the callouts in code blocks, i.e. "// <<1>>", to the first callout with the same
+// number in the text after the code block, and back. A callout in the text refers to the last code
+// block before it that has callouts.
+type Callouts struct {
+	// Comments is a list of comments that are used to detect callouts, as html.RendererOptions.Comments.
+	Comments [][]byte
+
+	blocks map[*ast.CodeBlock]int  // code block -> its number, only for blocks with callouts
+	text   map[*ast.Callout]int    // callout in the text -> number of the code block it refers to
+	code   map[string]bool         // "block-id" for the callouts in code blocks
+	first  map[string]*ast.Callout // "block-id" -> first callout in the text
+	seen   map[string]bool         // "block-id" for the callouts in code blocks that got an id
+}
+
+// NewCallouts finds the callouts in doc, comments are used to detect them in code blocks.
+func NewCallouts(doc ast.Node, comments [][]byte) *Callouts {
+	c := &Callouts{
+		Comments: comments,
+		blocks:   map[*ast.CodeBlock]int{},
+		text:     map[*ast.Callout]int{},
+		code:     map[string]bool{},
+		first:    map[string]*ast.Callout{},
+		seen:     map[string]bool{},
+	}
+	block := 0
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch node := node.(type) {
+		case *ast.CodeBlock:
+			ids := c.ids(node.Literal)
+			if len(ids) == 0 {
+				return ast.GoToNext
+			}
+			block++
+			c.blocks[node] = block
+			for _, id := range ids {
+				c.code[calloutKey(block, id)] = true
+			}
+		case *ast.Callout:
+			if block == 0 {
+				return ast.GoToNext
+			}
+			c.text[node] = block
+			key := calloutKey(block, node.ID)
+			if _, ok := c.first[key]; !ok && c.code[key] {
+				c.first[key] = node
+			}
+		}
+		return ast.GoToNext
+	})
+	return c
+}
+
+// RenderNode renders ast.CodeBlock and ast.Callout nodes, it can be registered as a mast.RenderFunc.
+// Code blocks are rendered without highlighting, see Highlighter for that.
+func (c *Callouts) RenderNode(w io.Writer, node ast.Node, entering bool) ast.WalkStatus {
+	switch node := node.(type) {
+	case *ast.CodeBlock:
+		return Highlighter{Comments: c.Comments, Callouts: c}.render(w, node, entering, false)
+	case *ast.Callout:
+		block, ok := c.text[node]
+		key := calloutKey(block, node.ID)
+		if !ok || !c.code[key] {
+			io.WriteString(w, `<span class="callout">`)
+			html.EscapeHTML(w, node.ID)
+			io.WriteString(w, "</span>")
+			return ast.GoToNext
+		}
+		io.WriteString(w, `<a class="callout"`)
+		if c.first[key] == node {
+			io.WriteString(w, ` id="callout-ref-`+key+`"`)
+		}
+		io.WriteString(w, ` href="#callout-`+key+`">`)
+		html.EscapeHTML(w, node.ID)
+		io.WriteString(w, "</a>")
+	}
+	return ast.GoToNext
+}
+
+// codeCallout writes the callout id found in block to w, it links to the first reference in the text.
+// Only the first callout with id in block gets an anchor.
+func (c *Callouts) codeCallout(w io.Writer, block *ast.CodeBlock, id []byte) {
+	key := calloutKey(c.blocks[block], id)
+	io.WriteString(w, `<span class="callout"`)
+	if !c.seen[key] {
+		io.WriteString(w, ` id="callout-`+key+`"`)
+		c.seen[key] = true
+	}
+	io.WriteString(w, ">")
+	if c.first[key] != nil {
+		io.WriteString(w, `<a href="#callout-ref-`+key+`">`)
+		html.EscapeHTML(w, id)
+		io.WriteString(w, "</a></span>")
+		return
+	}
+	html.EscapeHTML(w, id)
+	io.WriteString(w, "</span>")
+}
+
+// ids returns the IDs of the callouts in the code d.
+func (c *Callouts) ids(d []byte) [][]byte {
+	ids := [][]byte{}
+	for _, comment := range c.Comments {
+		for i := 0; i < len(d); {
+			j := bytes.Index(d[i:], comment)
+			if j < 0 {
+				break
+			}
+			i += j + len(comment)
+			if id, consumed := parser.IsCallout(d[i:]); consumed > 0 {
+				ids = append(ids, id)
+				i += consumed
+			}
+		}
+	}
+	return ids
+}
+
+func calloutKey(block int, id []byte) string {
+	return strconv.Itoa(block) + "-" + string(id)
+}
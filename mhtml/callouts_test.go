@@ -0,0 +1,53 @@
+package mhtml
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/mast"
+)
+
+func TestCallouts(t *testing.T) {
+	in := "~~~\nx //<<1>>\ny //<<2>>\n~~~\n\nSee <<1>>, <<1>> and <<3>>.\n"
+	p := parser.NewWithExtensions(parser.CommonExtensions | parser.Mmark)
+	doc := markdown.Parse([]byte(in), p)
+
+	comments := [][]byte{[]byte("//")}
+	c := NewCallouts(doc, comments)
+	renderers := mast.Renderers{}
+	renderers.Register(&ast.CodeBlock{}, c.RenderNode)
+	renderers.Register(&ast.Callout{}, c.RenderNode)
+	r := html.NewRenderer(html.RendererOptions{Comments: comments, RenderNodeHook: NewRenderHook(renderers, mast.UnknownChildren).RenderNode})
+	out := string(markdown.Render(doc, r))
+
+	for _, want := range []string{
+		`x <span class="callout" id="callout-1-1"><a href="#callout-ref-1-1">1</a></span>`,
+		`y <span class="callout" id="callout-1-2">2</span>`,
+		`See <a class="callout" id="callout-ref-1-1" href="#callout-1-1">1</a>, <a class="callout" href="#callout-1-1">1</a>`,
+		`and <span class="callout">3</span>.`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("want %q in output, got %s", want, out)
+		}
+	}
+}
+
+func TestBCP14(t *testing.T) {
+	strong := &ast.Strong{}
+	ast.AppendChild(strong, &ast.Text{Leaf: ast.Leaf{Literal: []byte("MUST")}})
+	mast.AddClass(strong, "bcp14")
+
+	BCP14Link = "#RFC2119"
+	defer func() { BCP14Link = "" }()
+
+	r := html.NewRenderer(html.RendererOptions{RenderNodeHook: RenderHook})
+	out := string(markdown.Render(strong, r))
+	want := `<strong class="bcp14"><a href="#RFC2119">MUST</a></strong>`
+	if out != want {
+		t.Errorf("want %q, got %q", want, out)
+	}
+}
@@ -0,0 +1,44 @@
+package mhtml
+
+import (
+	"io"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/mmarkdown/mmark/mast"
+)
+
+// CommentCSS is the CSS to include in the head of a complete page when the document has editorial
+// comments.
+const CommentCSS = `  <style>
+    span.cref { background: #ffc; border: 1px dashed #cc9; padding: 0 0.2em; font-style: italic; }
+    span.cref-source { font-weight: bold; font-style: normal; }
+  </style>
+`
+
+// HasComments returns true if doc contains editorial comments.
+func HasComments(doc ast.Node) bool {
+	found := false
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if _, ok := node.(*mast.Comment); ok {
+			found = true
+			return ast.Terminate
+		}
+		return ast.GoToNext
+	})
+	return found
+}
+
+// comment renders an editorial comment as an annotation, the source is shown before the text.
+func comment(w io.Writer, c *mast.Comment, entering bool) {
+	if !entering {
+		io.WriteString(w, "</span>")
+		return
+	}
+	io.WriteString(w, `<span class="cref">`)
+	if len(c.Source) > 0 {
+		io.WriteString(w, `<span class="cref-source">`)
+		html.EscapeHTML(w, c.Source)
+		io.WriteString(w, ":</span> ")
+	}
+}
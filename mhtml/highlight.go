@@ -0,0 +1,380 @@
+package mhtml
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/mast"
+)
+
+// Language describes the lexical structure of a language, as far as needed for syntax highlighting.
+type Language struct {
+	Keywords       []string    // Keywords, highlighted with class "hl-keyword".
+	Literals       []string    // Literals such as true and nil, highlighted with class "hl-literal".
+	LineComments   []string    // Line comment prefixes.
+	BlockComments  [][2]string // Block comment start and end markers.
+	Quotes         string      // Quote characters of single line strings, backslash escapes are honored.
+	RawQuotes      string      // Quote characters of multi line strings without escapes.
+	LongStrings    []string    // Delimiters of multi line strings, i.e. """ in Python.
+	NumberPrefixes []string    // Prefixes that start a number, besides a digit.
+	IdentChars     string      // Characters, besides letters, digits and _, that are allowed in identifiers.
+	IgnoreCase     bool        // Keywords and literals are case insensitive.
+
+	keywords map[string]string
+}
+
+// Languages holds the languages that are highlighted, keyed by the language in the info string of
+// the code block.
+var Languages = map[string]*Language{
+	"go": {
+		Keywords: []string{"break", "case", "chan", "const", "continue", "default", "defer", "else", "fallthrough",
+			"for", "func", "go", "goto", "if", "import", "interface", "map", "package", "range", "return", "select",
+			"struct", "switch", "type", "var"},
+		Literals:      []string{"true", "false", "nil", "iota"},
+		LineComments:  []string{"//"},
+		BlockComments: [][2]string{{"/*", "*/"}},
+		Quotes:        `"'`,
+		RawQuotes:     "`",
+	},
+	"c": {
+		Keywords: []string{"auto", "break", "case", "char", "const", "continue", "default", "do", "double", "else",
+			"enum", "extern", "float", "for", "goto", "if", "inline", "int", "long", "register", "restrict", "return",
+			"short", "signed", "sizeof", "static", "struct", "switch", "typedef", "union", "unsigned", "void",
+			"volatile", "while", "#include", "#define", "#if", "#ifdef", "#ifndef", "#else", "#endif"},
+		Literals:      []string{"NULL", "true", "false"},
+		LineComments:  []string{"//"},
+		BlockComments: [][2]string{{"/*", "*/"}},
+		Quotes:        `"'`,
+		IdentChars:    "#",
+	},
+	"python": {
+		Keywords: []string{"and", "as", "assert", "async", "await", "break", "class", "continue", "def", "del", "elif",
+			"else", "except", "finally", "for", "from", "global", "if", "import", "in", "is", "lambda", "nonlocal",
+			"not", "or", "pass", "raise", "return", "try", "while", "with", "yield"},
+		Literals:     []string{"True", "False", "None"},
+		LineComments: []string{"#"},
+		Quotes:       `"'`,
+		LongStrings:  []string{`"""`, `'''`},
+	},
+	"shell": {
+		Keywords: []string{"if", "then", "else", "elif", "fi", "for", "in", "do", "done", "case", "esac", "while",
+			"until", "function", "return", "local", "export", "exit", "echo", "cd", "set", "unset", "shift"},
+		LineComments: []string{"#"},
+		Quotes:       `"`,
+		RawQuotes:    `'`,
+		IdentChars:   "-",
+	},
+	"json": {
+		Literals: []string{"true", "false", "null"},
+		Quotes:   `"`,
+	},
+	"abnf": {
+		Keywords: []string{"ALPHA", "BIT", "CHAR", "CR", "CRLF", "CTL", "DIGIT", "DQUOTE", "HEXDIG", "HTAB", "LF",
+			"LWSP", "OCTET", "SP", "VCHAR", "WSP"},
+		LineComments:   []string{";"},
+		RawQuotes:      `"`,
+		NumberPrefixes: []string{"%x", "%d", "%b", "%s", "%i"},
+		IdentChars:     "-",
+		IgnoreCase:     true,
+	},
+	"yang": {
+		Keywords: []string{"action", "anydata", "anyxml", "augment", "base", "belongs-to", "bit", "case", "choice",
+			"config", "contact", "container", "default", "description", "deviate", "deviation", "enum",
+			"error-app-tag", "error-message", "extension", "feature", "fraction-digits", "grouping", "identity",
+			"if-feature", "import", "include", "input", "key", "leaf", "leaf-list", "length", "list", "mandatory",
+			"max-elements", "min-elements", "modifier", "module", "must", "namespace", "notification", "ordered-by",
+			"organization", "output", "path", "pattern", "position", "prefix", "presence", "range", "reference",
+			"refine", "require-instance", "revision", "revision-date", "rpc", "status", "submodule", "type",
+			"typedef", "unique", "units", "uses", "value", "when", "yang-version", "yin-element"},
+		Literals:      []string{"true", "false", "current", "deprecated", "obsolete"},
+		LineComments:  []string{"//"},
+		BlockComments: [][2]string{{"/*", "*/"}},
+		Quotes:        `"`,
+		RawQuotes:     `'`,
+		IdentChars:    "-",
+	},
+}
+
+func init() {
+	Languages["golang"] = Languages["go"]
+	Languages["py"] = Languages["python"]
+	Languages["sh"] = Languages["shell"]
+	Languages["bash"] = Languages["shell"]
+}
+
+// keyword returns the highlight class of ident, or the empty string if ident is not a keyword or literal.
+func (l *Language) keyword(ident string) string {
+	if l.keywords == nil {
+		l.keywords = map[string]string{}
+		for _, k := range l.Keywords {
+			l.keywords[l.fold(k)] = "hl-keyword"
+		}
+		for _, k := range l.Literals {
+			l.keywords[l.fold(k)] = "hl-literal"
+		}
+	}
+	return l.keywords[l.fold(ident)]
+}
+
+func (l *Language) fold(s string) string {
+	if l.IgnoreCase {
+		return strings.ToLower(s)
+	}
+	return s
+}
+
+func (l *Language) isIdent(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		strings.IndexByte(l.IdentChars, c) >= 0
+}
+
+// Highlighter renders code blocks with syntax highlighting. Callouts are detected as the HTML renderer does.
+type Highlighter struct {
+	// Comments is a list of comments that are used to detect callouts, as html.RendererOptions.Comments.
+	Comments [][]byte
+	// Callouts, if not nil, links the callouts in the code to the ones in the text.
+	Callouts *Callouts
+
+	block *ast.CodeBlock // code block being rendered
+}
+
+// RenderNode renders an ast.CodeBlock, it can be registered as a mast.RenderFunc. Code blocks in a
+// language not in Languages are rendered without highlighting.
+func (h Highlighter) RenderNode(w io.Writer, node ast.Node, entering bool) ast.WalkStatus {
+	codeBlock, ok := node.(*ast.CodeBlock)
+	if !ok {
+		return ast.GoToNext
+	}
+	return h.render(w, codeBlock, entering, true)
+}
+
+// render renders codeBlock, it is only highlighted if highlight is true.
+func (h Highlighter) render(w io.Writer, codeBlock *ast.CodeBlock, entering, highlight bool) ast.WalkStatus {
+	if !entering {
+		return ast.GoToNext
+	}
+	h.block = codeBlock
+
+	lang := language(codeBlock.Info)
+	classes := mast.Classes(codeBlock)
+	if lang != "" {
+		classes = append(classes, "language-"+lang)
+	}
+	attrs := []string{}
+	if len(classes) > 0 {
+		attrs = append(attrs, `class="`+strings.Join(classes, " ")+`"`)
+	}
+	for _, a := range html.BlockAttrs(codeBlock) {
+		switch {
+		case strings.HasPrefix(a, "class="):
+		case strings.HasPrefix(a, "markers="):
+			// The <CODE BEGINS> and <CODE ENDS> markers of RFC 7991, HTML has nothing like it.
+		case strings.HasPrefix(a, "name="):
+			// Not an attribute of <code>, the file name is kept as data.
+			attrs = append(attrs, "data-"+a)
+		default:
+			attrs = append(attrs, a)
+		}
+	}
+
+	io.WriteString(w, "\n<pre><code")
+	if len(attrs) > 0 {
+		io.WriteString(w, " "+strings.Join(attrs, " "))
+	}
+	io.WriteString(w, ">")
+	if l, ok := Languages[strings.ToLower(lang)]; ok && highlight {
+		h.highlight(w, codeBlock.Literal, l)
+	} else {
+		h.escape(w, codeBlock.Literal)
+	}
+	io.WriteString(w, "</code></pre>\n")
+	return ast.GoToNext
+}
+
+// highlight writes the highlighted code d to w.
+func (h Highlighter) highlight(w io.Writer, d []byte, l *Language) {
+	for i := 0; i < len(d); {
+		if n := h.callout(w, d[i:]); n > 0 {
+			i += n
+			continue
+		}
+
+		start := i
+		class := ""
+		switch {
+		case hasAnyPrefix(d[i:], l.LongStrings) != "":
+			delim := hasAnyPrefix(d[i:], l.LongStrings)
+			i = until(d, i+len(delim), delim)
+			class = "hl-string"
+		case blockComment(d[i:], l.BlockComments) != nil:
+			c := blockComment(d[i:], l.BlockComments)
+			i = until(d, i+len(c[0]), c[1])
+			class = "hl-comment"
+		case hasAnyPrefix(d[i:], l.LineComments) != "":
+			for i < len(d) && d[i] != '\n' {
+				i++
+			}
+			class = "hl-comment"
+		case strings.IndexByte(l.RawQuotes, d[i]) >= 0:
+			i = until(d, i+1, string(d[i]))
+			class = "hl-string"
+		case strings.IndexByte(l.Quotes, d[i]) >= 0:
+			i = quoted(d, i)
+			class = "hl-string"
+		case hasAnyPrefix(d[i:], l.NumberPrefixes) != "" || d[i] >= '0' && d[i] <= '9':
+			i += len(hasAnyPrefix(d[i:], l.NumberPrefixes))
+			for i < len(d) && (isHex(d[i]) || d[i] == '.' || d[i] == 'x' || d[i] == 'X' || d[i] == '-' && l.NumberPrefixes != nil) {
+				i++
+			}
+			if i == start {
+				i++
+			}
+			class = "hl-number"
+		case l.isIdent(d[i]):
+			for i < len(d) && l.isIdent(d[i]) {
+				i++
+			}
+			class = l.keyword(string(d[start:i]))
+		default:
+			i++
+		}
+
+		if class == "" {
+			h.escape(w, d[start:i])
+			continue
+		}
+		io.WriteString(w, `<span class="`+class+`">`)
+		h.escape(w, d[start:i])
+		io.WriteString(w, "</span>")
+	}
+}
+
+// escape writes html-escaped d to w, callouts are rendered as the HTML renderer does.
+func (h Highlighter) escape(w io.Writer, d []byte) {
+	for i := 0; i < len(d); i++ {
+		if n := h.callout(w, d[i:]); n > 0 {
+			i += n - 1
+			continue
+		}
+		html.EscapeHTML(w, d[i:i+1])
+	}
+}
+
+// callout writes the callout at the start of d to w, and returns the number of bytes consumed. If
+// there is no callout nothing is written and 0 is returned.
+func (h Highlighter) callout(w io.Writer, d []byte) int {
+	for _, comment := range h.Comments {
+		if !bytes.HasPrefix(d, comment) {
+			continue
+		}
+		if id, consumed := parser.IsCallout(d[len(comment):]); consumed > 0 {
+			if h.Callouts != nil {
+				h.Callouts.codeCallout(w, h.block, id)
+				return len(comment) + consumed
+			}
+			io.WriteString(w, `<span class="callout">`)
+			w.Write(id)
+			io.WriteString(w, "</span>")
+			return len(comment) + consumed
+		}
+	}
+	return 0
+}
+
+func hasAnyPrefix(d []byte, prefixes []string) string {
+	for _, p := range prefixes {
+		if bytes.HasPrefix(d, []byte(p)) {
+			return p
+		}
+	}
+	return ""
+}
+
+func blockComment(d []byte, comments [][2]string) *[2]string {
+	for i := range comments {
+		if bytes.HasPrefix(d, []byte(comments[i][0])) {
+			return &comments[i]
+		}
+	}
+	return nil
+}
+
+// until returns the index just after end, searching from i. If end is not found len(d) is returned.
+func until(d []byte, i int, end string) int {
+	if i > len(d) {
+		return len(d)
+	}
+	j := bytes.Index(d[i:], []byte(end))
+	if j < 0 {
+		return len(d)
+	}
+	return i + j + len(end)
+}
+
+// quoted returns the index just after the string starting at i, the string ends at the matching quote
+// or at the end of the line.
+func quoted(d []byte, i int) int {
+	q := d[i]
+	for i++; i < len(d); i++ {
+		switch d[i] {
+		case '\\':
+			i++
+		case q:
+			return i + 1
+		case '\n':
+			return i
+		}
+	}
+	return len(d)
+}
+
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+// HighlightStyles holds the CSS for the highlight classes, per style.
+var HighlightStyles = map[string]map[string]string{
+	"light": {
+		"hl-keyword": "color: #0000a0; font-weight: bold;",
+		"hl-literal": "color: #800080;",
+		"hl-string":  "color: #a31515;",
+		"hl-comment": "color: #008000; font-style: italic;",
+		"hl-number":  "color: #098658;",
+	},
+	"dark": {
+		"hl-keyword": "color: #569cd6; font-weight: bold;",
+		"hl-literal": "color: #c586c0;",
+		"hl-string":  "color: #ce9178;",
+		"hl-comment": "color: #6a9955; font-style: italic;",
+		"hl-number":  "color: #b5cea8;",
+	},
+}
+
+// HighlightCSS returns the CSS for style. The style "classes" returns no CSS, only the classes are
+// output and the style sheet should define them.
+func HighlightCSS(style string) ([]byte, error) {
+	if style == "classes" {
+		return nil, nil
+	}
+	s, ok := HighlightStyles[style]
+	if !ok {
+		return nil, fmt.Errorf("unknown highlight style %q", style)
+	}
+	classes := make([]string, 0, len(s))
+	for c := range s {
+		classes = append(classes, c)
+	}
+	sort.Strings(classes)
+
+	buf := &bytes.Buffer{}
+	for _, c := range classes {
+		fmt.Fprintf(buf, "    .%s { %s }\n", c, s[c])
+	}
+	return buf.Bytes(), nil
+}
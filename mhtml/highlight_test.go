@@ -0,0 +1,69 @@
+package mhtml
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/mast"
+)
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		info, code, want string
+	}{
+		{
+			"go", "return nil // x\n",
+			`<span class="hl-keyword">return</span> <span class="hl-literal">nil</span> <span class="hl-comment">// x</span>` + "\n",
+		},
+		{
+			"go", `s := "a<b" //<<1>>`,
+			`s := <span class="hl-string">&quot;a&lt;b&quot;</span> <span class="callout">1</span>`,
+		},
+		{
+			"python", "x = 1 #<<2>>",
+			`x = <span class="hl-number">1</span> <span class="callout">2</span>`,
+		},
+		{
+			"unknown", "a < b //<<3>>",
+			`a &lt; b <span class="callout">3</span>`,
+		},
+	}
+	h := Highlighter{Comments: [][]byte{[]byte("//"), []byte("#")}}
+	for _, tc := range tests {
+		buf := &bytes.Buffer{}
+		cb := &ast.CodeBlock{Info: []byte(tc.info)}
+		cb.Literal = []byte(tc.code)
+		h.RenderNode(buf, cb, true)
+
+		want := "\n<pre><code class=\"language-" + tc.info + "\">" + tc.want + "</code></pre>\n"
+		if got := buf.String(); got != want {
+			t.Errorf("want %q, got %q", want, got)
+		}
+	}
+}
+
+func TestCodeBlockClass(t *testing.T) {
+	cb := &ast.CodeBlock{Info: []byte("go")}
+	cb.Literal = []byte("x < y\n")
+	mast.AttributeInit(cb)
+	mast.AddClass(cb, "example")
+	mast.SetAttribute(cb, "name", []byte("x.go"))
+	mast.SetAttribute(cb, "markers", []byte("true"))
+	doc := &ast.Document{}
+	ast.AppendChild(doc, cb)
+
+	// The code block renderers mmark registers, with and without -highlight.
+	renderers := []mast.RenderFunc{Highlighter{}.RenderNode, NewCallouts(doc, nil).RenderNode}
+	want := "\n<pre><code class=\"example language-go\" data-name=\"x.go\">x &lt; y\n</code></pre>\n"
+	for i, fn := range renderers {
+		buf := &bytes.Buffer{}
+		fn(buf, cb, true)
+		if got := buf.String(); got != want {
+			t.Errorf("want %q, got %q, for renderer %d", want, got, i)
+		}
+	}
+	if string(cb.Info) != "go" || len(mast.Classes(cb)) != 1 {
+		t.Errorf("want the code block unchanged, got info %q and classes %v", cb.Info, mast.Classes(cb))
+	}
+}
@@ -1,51 +1,111 @@
 package mhtml
 
 import (
-	"fmt"
+	"bytes"
 	"io"
+	"log"
+	"reflect"
 
 	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/mmarkdown/mmark/lang"
+	"github.com/mmarkdown/mmark/latex"
 	"github.com/mmarkdown/mmark/mast"
+	"github.com/mmarkdown/mmark/transform"
 )
 
 var (
 	// IndexReturnLinkContents is the string to use for index item return links.
 	IndexReturnLinkContents = "<sup>[go]</sup>"
+
+	// Labels holds the labels used in the generated HTML, i.e. for the footnotes heading.
+	Labels = lang.New("en")
+
+	// BCP14Link, if not empty, turns the BCP 14 keywords into links to it, i.e. "#RFC2119" or
+	// "https://www.rfc-editor.org/info/bcp14".
+	BCP14Link = ""
 )
 
 // RenderHook is used to render mmark specific AST nodes.
 func RenderHook(w io.Writer, node ast.Node, entering bool) (ast.WalkStatus, bool) {
 	switch node := node.(type) {
+	case *ast.Document:
+		if entering {
+			return ast.GoToNext, true
+		}
+		// Authors' addresses are put at the end of the document.
+		if t := findTitle(node); t != nil {
+			authorsAddresses(w, t)
+		}
+		return ast.GoToNext, true
 	case *ast.Footnotes:
 		if !entering {
 			io.WriteString(w, "</h1>\n")
 			return ast.GoToNext, true
 		}
-		io.WriteString(w, `<h1 id="footnote-section">Footnotes`)
-	case *mast.Bibliography:
+		io.WriteString(w, `<h1 id="footnote-section">`)
+		html.EscapeHTML(w, []byte(Labels.Footnotes))
+	case *ast.Strong:
+		// The bcp14 transformation pass marks the BCP 14 keywords.
+		if !mast.AttributeClass(node, transform.BCP14Class) {
+			return ast.GoToNext, false
+		}
 		if !entering {
-			io.WriteString(w, "</dl>\n")
+			if BCP14Link != "" {
+				io.WriteString(w, "</a>")
+			}
+			io.WriteString(w, "</strong>")
 			return ast.GoToNext, true
 		}
-		io.WriteString(w, "<h1 id=\"bibliography-section\">Bibliography</h1>\n<div class=\"bibliography\">\n")
-		io.WriteString(w, "<dl>\n")
+		io.WriteString(w, `<strong class="`+transform.BCP14Class+`">`)
+		if BCP14Link != "" {
+			io.WriteString(w, `<a href="`)
+			html.EscapeHTML(w, []byte(BCP14Link))
+			io.WriteString(w, `">`)
+		}
 		return ast.GoToNext, true
-	case *mast.BibliographyItem:
-		if !entering {
-			return ast.GoToNext, true
+	case *mast.Raw:
+		if node.Format == "html" {
+			w.Write(node.Literal)
+		}
+		return ast.GoToNext, true
+	case *ast.TableCell:
+		return ast.GoToNext, tableCell(w, node, entering)
+	case *ast.Math:
+		return mathML(w, node.Literal, false)
+	case *ast.MathBlock:
+		// The block is rendered completely when entering, also when the math can't be converted.
+		if entering {
+			mathBlock(w, node)
+		}
+		return ast.GoToNext, true
+	case *mast.Bibliography, *mast.BibliographyItem:
+		return (&Bibliography{}).RenderNode(w, node, entering), true
+	case *mast.ArtSet:
+		// Only the SVG is shown, the ascii-art is for text renditions.
+		if entering {
+			io.WriteString(w, "\n<div class=\"artset\">\n")
+			w.Write(node.SVG)
+			io.WriteString(w, "\n</div>\n")
 		}
-		bibliographyItem(w, node, entering)
+		return ast.SkipChildren, true
+	case *mast.Comment:
+		comment(w, node, entering)
 		return ast.GoToNext, true
 	case *mast.Title:
-		// outout toml title block in html.
-		//title(w, node, entering)
+		// output toml title block in html.
+		title(w, node, entering)
 		return ast.GoToNext, true
+	case *mast.TableOfContents:
+		return TOC{Depth: TOCDepth}.RenderNode(w, node, entering), true
 	case *mast.DocumentIndex:
 		if !entering {
 			io.WriteString(w, "\n</div>\n")
 			return ast.GoToNext, true
 		}
-		io.WriteString(w, "<h1 id=\"index-section\">Index</h1>\n<div class=\"index\">\n")
+		io.WriteString(w, "<h1 id=\"index-section\">")
+		html.EscapeHTML(w, []byte(Labels.Index))
+		io.WriteString(w, "</h1>\n<div class=\"index\">\n")
 		return ast.GoToNext, true
 	case *mast.IndexLetter:
 		if !entering {
@@ -97,18 +157,72 @@ func RenderHook(w io.Writer, node ast.Node, entering bool) (ast.WalkStatus, bool
 	return ast.GoToNext, false
 }
 
-func bibliographyItem(w io.Writer, bib *mast.BibliographyItem, entering bool) {
-	io.WriteString(w, `<dt class="bibliography-cite" id="`+string(bib.Anchor)+`">`+fmt.Sprintf("[%s]", bib.Anchor)+"</dt>\n")
-	io.WriteString(w, `<dd>`)
-	io.WriteString(w, `<span class="bibliography-author">`+bib.Reference.Front.Author.Fullname+"</span>\n")
-	io.WriteString(w, `<span class="bibliography-title">`+bib.Reference.Front.Title+"</span>\n")
-	if bib.Reference.Format.Target != "" {
-		io.WriteString(w, `<a class="bliography-target" href="`+bib.Reference.Format.Target+"\">"+bib.Reference.Format.Target+"</a>\n")
+// astPkgPath is the package path of the nodes the HTML renderer knows how to render.
+var astPkgPath = reflect.TypeOf(ast.Document{}).PkgPath()
+
+// Hook renders the node types registered in its renderers with their functions, numbers sections,
+// figures and tables (see Numbering) and falls back to RenderHook for the others. Nodes that are
+// still not handled and are unknown to the HTML renderer are dealt with according to its UnknownNode.
+// Its RenderNode method is used as the RenderNodeHook of the HTML renderer.
+type Hook struct {
+	// HeadingIDPrefix and HeadingIDSuffix are added to the IDs of the numbered headings and to the
+	// links to headings, they should be the same as in the options of the HTML renderer.
+	HeadingIDPrefix string
+	HeadingIDSuffix string
+
+	renderers mast.Renderers
+	unknown   mast.UnknownNode
+	numbering *Numbering
+	err       error // set when rendering is stopped because of an unknown node
+}
+
+// NewRenderHook returns a Hook that renders the node types registered in renderers and deals with
+// unknown nodes according to unknown.
+func NewRenderHook(renderers mast.Renderers, unknown mast.UnknownNode) *Hook {
+	return &Hook{renderers: renderers, unknown: unknown}
+}
+
+// RenderNode renders node, it has the signature of an html.RenderNodeFunc.
+func (h *Hook) RenderNode(w io.Writer, node ast.Node, entering bool) (ast.WalkStatus, bool) {
+	if fn := h.renderers.Lookup(node); fn != nil {
+		return fn(w, node, entering), true
+	}
+	if doc, ok := node.(*ast.Document); ok && entering {
+		h.numbering = NewNumbering(doc)
+		h.numbering.HeadingIDPrefix, h.numbering.HeadingIDSuffix = h.HeadingIDPrefix, h.HeadingIDSuffix
+	}
+	if h.numbering != nil {
+		if status, handled := h.numbering.RenderNode(w, node, entering); handled {
+			return status, true
+		}
 	}
-	if bib.Reference.Front.Date.Year != "" {
-		io.WriteString(w, `<date class="bibliography-date">`+bib.Reference.Front.Date.Year+"</date>\n")
+	if _, ok := node.(*mast.TableOfContents); ok {
+		toc := TOC{Depth: TOCDepth, HeadingIDPrefix: h.HeadingIDPrefix, HeadingIDSuffix: h.HeadingIDSuffix}
+		return toc.RenderNode(w, node, entering), true
 	}
-	io.WriteString(w, "</dd>\n")
+	if status, handled := RenderHook(w, node, entering); handled {
+		return status, true
+	}
+	t := reflect.TypeOf(node)
+	if t.Kind() == reflect.Ptr && t.Elem().PkgPath() == astPkgPath {
+		return ast.GoToNext, false
+	}
+	status, err := h.unknown.Unknown(node, entering)
+	if err != nil {
+		h.err = err
+	}
+	return status, true
+}
+
+// Err returns the error that stopped the rendering, if any.
+func (h *Hook) Err() error { return h.err }
+
+// language returns the language from the info string of a code block.
+func language(info []byte) string {
+	if i := bytes.IndexAny(info, "\t "); i >= 0 {
+		return string(info[:i])
+	}
+	return string(info)
 }
 
 func firstSubItem(node ast.Node) bool {
@@ -140,3 +254,32 @@ func lastSubItem(node ast.Node) bool {
 	}
 	return true
 }
+
+// mathBlock outputs the math block as MathML, if it can't be converted it's output as the HTML
+// renderer does, for MathJax.
+func mathBlock(w io.Writer, node *ast.MathBlock) {
+	if _, ok := mathML(w, node.Literal, true); ok {
+		return
+	}
+	io.WriteString(w, `<p><span class="math display">\[`)
+	html.EscapeHTML(w, node.Literal)
+	io.WriteString(w, `\]</span></p>`)
+}
+
+// mathML outputs the LaTeX math in literal as MathML. If the math can't be converted, it's left to the
+// HTML renderer, which outputs it for MathJax.
+func mathML(w io.Writer, literal []byte, display bool) (ast.WalkStatus, bool) {
+	m, err := latex.MathML(string(literal), display)
+	if err != nil {
+		log.Printf("Failed to convert math %q: %s, leaving it for MathJax", literal, err)
+		return ast.GoToNext, false
+	}
+	if display {
+		io.WriteString(w, "\n")
+		w.Write(m)
+		io.WriteString(w, "\n")
+		return ast.GoToNext, true
+	}
+	w.Write(m)
+	return ast.GoToNext, true
+}
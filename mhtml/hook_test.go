@@ -0,0 +1,83 @@
+package mhtml
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/mast"
+)
+
+type custom struct {
+	ast.Container
+}
+
+func customDoc() ast.Node {
+	doc := &ast.Document{}
+	c := &custom{}
+	ast.AppendChild(doc, c)
+	ast.AppendChild(c, &ast.Text{Leaf: ast.Leaf{Literal: []byte("text")}})
+	ast.AppendChild(doc, &ast.Text{Leaf: ast.Leaf{Literal: []byte(" after")}})
+	return doc
+}
+
+func TestRenderHookUnknownNode(t *testing.T) {
+	tests := []struct {
+		unknown mast.UnknownNode
+		want    string
+		err     bool
+	}{
+		{mast.UnknownChildren, "text after", false},
+		{mast.UnknownSkip, "after", false},
+		{mast.UnknownError, "", true},
+	}
+	for _, tc := range tests {
+		hook := NewRenderHook(nil, tc.unknown)
+		r := html.NewRenderer(html.RendererOptions{RenderNodeHook: hook.RenderNode})
+		got := strings.TrimSpace(string(markdown.Render(customDoc(), r)))
+		if got != tc.want {
+			t.Errorf("want %q, got %q, for %d", tc.want, got, tc.unknown)
+		}
+		if (hook.Err() != nil) != tc.err {
+			t.Errorf("want error %t, got %v, for %d", tc.err, hook.Err(), tc.unknown)
+		}
+	}
+}
+
+func TestRenderHookRegister(t *testing.T) {
+	renderers := mast.Renderers{}
+	renderers.Register(&custom{}, func(w io.Writer, node ast.Node, entering bool) ast.WalkStatus {
+		if entering {
+			io.WriteString(w, "<custom/>")
+		}
+		return ast.SkipChildren
+	})
+	hook := NewRenderHook(renderers, mast.UnknownError)
+	r := html.NewRenderer(html.RendererOptions{RenderNodeHook: hook.RenderNode})
+	got := strings.TrimSpace(string(markdown.Render(customDoc(), r)))
+	if want := "<custom/> after"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if hook.Err() != nil {
+		t.Errorf("want no error, got %v", hook.Err())
+	}
+}
+
+func TestRenderHookMathBlock(t *testing.T) {
+	for in, want := range map[string]string{
+		"$$\nx^2\n$$\n":     ` display="block">`,
+		"$$\n\\frac{\n$$\n": "<p><span class=\"math display\">\\[\n\\frac{\n\\]</span></p>",
+	} {
+		p := parser.NewWithExtensions(parser.CommonExtensions | parser.MathJax)
+		doc := markdown.Parse([]byte(in), p)
+		r := html.NewRenderer(html.RendererOptions{RenderNodeHook: RenderHook})
+		got := string(markdown.Render(doc, r))
+		if strings.Count(got, want) != 1 || strings.Count(got, "</p>") > 1 {
+			t.Errorf("want %q once in %q", want, got)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package mhtml
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/mmarkdown/mmark/mast"
+)
+
+// Numbering holds the RFC style numbers of the sections, figures and tables of a document, and the
+// labels used when cross referencing them.
+type Numbering struct {
+	sections map[*ast.Heading]string       // "2.3." or "Appendix A."
+	figures  map[*ast.CaptionFigure]string // "Figure 3" or "Table 2"
+	labels   map[string]string             // id -> "Section 2.3"
+	headings map[string]bool               // IDs of the headings
+
+	// Href returns the link target for id, if nil "#id" is used, with the HeadingIDPrefix and
+	// HeadingIDSuffix added to the IDs of headings.
+	Href func(id string) string
+	// HeadingIDPrefix and HeadingIDSuffix are added to the IDs of the headings, as the HTML renderer
+	// options of the same name do.
+	HeadingIDPrefix string
+	HeadingIDSuffix string
+}
+
+// NewNumbering numbers the sections, figures and tables in doc. Sections are numbered as in the
+// table of contents. Figures and tables are numbered separately, from the start of the document.
+func NewNumbering(doc ast.Node) *Numbering {
+	n := &Numbering{
+		sections: map[*ast.Heading]string{},
+		figures:  map[*ast.CaptionFigure]string{},
+		labels:   map[string]string{},
+		headings: map[string]bool{},
+	}
+
+	numbers := headingNumbers(doc)
+	matter := ast.DocumentMatterMain
+	figure, table := 0, 0
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch node := node.(type) {
+		case *ast.DocumentMatter:
+			matter = node.Matter
+		case *ast.Heading:
+			label := headingText(node)
+			if num, ok := numbers[node]; ok {
+				if matter == ast.DocumentMatterBack {
+					label = Labels.Appendix + " " + num
+					n.sections[node] = label + "."
+				} else {
+					label = Labels.Section + " " + num
+					n.sections[node] = num + "."
+				}
+			}
+			if id := headingID(node); id != "" {
+				n.labels[id] = label
+				n.headings[id] = true
+			}
+		case *ast.CaptionFigure:
+			var label string
+			switch figureKind(node) {
+			case "":
+				return ast.GoToNext
+			case "table":
+				table++
+				label = Labels.Table + " " + strconv.Itoa(table)
+			default:
+				figure++
+				label = Labels.Figure + " " + strconv.Itoa(figure)
+			}
+			n.figures[node] = label
+			if id := figureID(node); id != "" {
+				n.labels[id] = label
+			}
+			// Subfigures are not numbered.
+			return ast.SkipChildren
+		}
+		return ast.GoToNext
+	})
+	return n
+}
+
+// Label returns the text used when cross referencing id, i.e. "Section 2.3", or the empty string
+// if id is not known.
+func (n *Numbering) Label(id string) string { return n.labels[id] }
+
+// RenderNode renders the numbers of headings, figures and tables and the labels of cross
+// references. It can be used as a render hook.
+func (n *Numbering) RenderNode(w io.Writer, node ast.Node, entering bool) (ast.WalkStatus, bool) {
+	switch node := node.(type) {
+	case *ast.Heading:
+		num, ok := n.sections[node]
+		if !ok {
+			return ast.GoToNext, false
+		}
+		n.heading(w, node, num, entering)
+		return ast.GoToNext, true
+	case *ast.Caption:
+		figure, ok := node.Parent.(*ast.CaptionFigure)
+		if !ok || n.figures[figure] == "" {
+			return ast.GoToNext, false
+		}
+		if !entering {
+			io.WriteString(w, "</figcaption>")
+			return ast.GoToNext, true
+		}
+		io.WriteString(w, `<figcaption><span class="figure-number">`+n.figures[figure]+":</span> ")
+		return ast.GoToNext, true
+	case *ast.CrossReference:
+		if !entering {
+			io.WriteString(w, "</a>")
+			return ast.GoToNext, true
+		}
+		io.WriteString(w, `<a class="xref" href="`)
+		html.EscapeHTML(w, []byte(n.href(string(node.Destination))))
+		io.WriteString(w, `">`)
+		if len(node.GetChildren()) > 0 {
+			return ast.GoToNext, true
+		}
+		label := n.Label(string(node.Destination))
+		if label == "" {
+			label = string(node.Destination)
+		}
+		html.EscapeHTML(w, []byte(label))
+		return ast.GoToNext, true
+	}
+	return ast.GoToNext, false
+}
+
+// href returns the link target for id.
+func (n *Numbering) href(id string) string {
+	if n.Href != nil {
+		return n.Href(id)
+	}
+	if n.headings[id] {
+		id = n.HeadingIDPrefix + id + n.HeadingIDSuffix
+	}
+	return "#" + id
+}
+
+func (n *Numbering) heading(w io.Writer, h *ast.Heading, num string, entering bool) {
+	level := h.Level
+	if level < 1 {
+		level = 1
+	}
+	if level > 6 {
+		level = 6
+	}
+	tag := "h" + strconv.Itoa(level)
+	if !entering {
+		io.WriteString(w, "</"+tag+">\n")
+		return
+	}
+
+	io.WriteString(w, "\n<"+tag)
+	if id := headingID(h); id != "" {
+		io.WriteString(w, ` id="`)
+		html.EscapeHTML(w, []byte(n.HeadingIDPrefix+id+n.HeadingIDSuffix))
+		io.WriteString(w, `"`)
+	}
+	for _, a := range html.BlockAttrs(h) {
+		// The id is written above, so there is only one.
+		if !strings.HasPrefix(a, "id=") {
+			io.WriteString(w, " "+a)
+		}
+	}
+	io.WriteString(w, ">")
+	io.WriteString(w, `<span class="section-number">`+num+"</span> ")
+}
+
+// headingID returns the ID of the heading h. As in the XML output, an id block attribute takes
+// precedence over the heading ID.
+func headingID(h *ast.Heading) string {
+	if id := mast.Attribute(h, "id"); id != nil {
+		return string(id)
+	}
+	return h.HeadingID
+}
+
+// figureID returns the ID of the figure, this is either set on the figure itself or on its first child.
+func figureID(figure *ast.CaptionFigure) string {
+	if id := mast.Attribute(figure, "id"); id != nil {
+		return string(id)
+	}
+	if first := ast.GetFirstChild(figure); first != nil {
+		return string(mast.Attribute(first, "id"))
+	}
+	return ""
+}
+
+// figureKind returns "table" when figure holds a table, the empty string when it holds a quote and
+// "figure" otherwise.
+func figureKind(figure *ast.CaptionFigure) string {
+	for _, child := range figure.GetChildren() {
+		switch child.(type) {
+		case *ast.Table:
+			return "table"
+		case *ast.BlockQuote:
+			return ""
+		}
+	}
+	return "figure"
+}
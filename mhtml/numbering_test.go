@@ -0,0 +1,85 @@
+package mhtml
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+func TestNumbering(t *testing.T) {
+	in := `.# Abstract
+
+{mainmatter}
+
+# Intro
+
+## Sub {#sub}
+
+{#fig}
+~~~
+x
+~~~
+Figure: A figure.
+
+{#tab}
+| a |
+|---|
+| 1 |
+Table: A table.
+
+{backmatter}
+
+# App {#app}
+`
+	p := parser.NewWithExtensions(parser.CommonExtensions | parser.AutoHeadingIDs | parser.Mmark | parser.Attributes)
+	doc := markdown.Parse([]byte(in), p)
+	n := NewNumbering(doc)
+
+	tests := map[string]string{
+		"abstract": "Abstract",
+		"intro":    "Section 1",
+		"sub":      "Section 1.1",
+		"fig":      "Figure 1",
+		"tab":      "Table 1",
+		"app":      "Appendix A",
+		"unknown":  "",
+	}
+	for id, want := range tests {
+		if got := n.Label(id); got != want {
+			t.Errorf("want %q, got %q, for %s", want, got, id)
+		}
+	}
+}
+
+func TestNumberingHeadingID(t *testing.T) {
+	in := "{#myid .x}\n# Three {#hid}\n\n# Four {#a\"b}\n"
+	p := parser.NewWithExtensions(parser.CommonExtensions | parser.Mmark | parser.Attributes)
+	doc := markdown.Parse([]byte(in), p)
+	n := NewNumbering(doc)
+	n.HeadingIDPrefix = "pre-"
+
+	buf := &bytes.Buffer{}
+	for _, child := range doc.GetChildren() {
+		n.RenderNode(buf, child, true)
+	}
+	want := "\n" + `<h1 id="pre-myid" class="x"><span class="section-number">1.</span> ` +
+		"\n" + `<h1 id="pre-a&quot;b"><span class="section-number">2.</span> `
+	if got := buf.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	if got := n.Label("myid"); got != "Section 1" {
+		t.Errorf("want %q, got %q", "Section 1", got)
+	}
+
+	buf.Reset()
+	n.HeadingIDSuffix = "-suf"
+	n.RenderNode(buf, &ast.CrossReference{Destination: []byte("myid")}, true)
+	n.RenderNode(buf, &ast.CrossReference{Destination: []byte("fig")}, true)
+	want = `<a class="xref" href="#pre-myid-suf">Section 1<a class="xref" href="#fig">fig`
+	if got := buf.String(); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,185 @@
+package mhtml
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/mmarkdown/mmark/mast"
+)
+
+// SearchEntry is a single section in the search index.
+type SearchEntry struct {
+	ID      string   `json:"id,omitempty"`
+	Href    string   `json:"href"`
+	Heading string   `json:"heading"`
+	Text    string   `json:"text"`
+	Terms   []string `json:"terms,omitempty"` // Index terms, i.e. "item" or "item, subitem".
+}
+
+// Search files written in the directory of a book, see SearchFiles for a single page.
+const (
+	SearchIndexFile  = "search.json"
+	SearchScriptFile = "search.js"
+)
+
+// SearchFiles returns the names of the search index and script for the single page HTML of the
+// document in file: "doc.md" has "doc.search.json" and "doc.search.js", so the files of documents in
+// the same directory don't overwrite each other.
+func SearchFiles(file string) (index, script string) {
+	base := strings.TrimSuffix(file, filepath.Ext(file))
+	return base + ".search.json", base + ".search.js"
+}
+
+// NewSearchIndex returns the search index of doc, with an entry for each section. The text before the
+// first heading is put in an entry with the title of the document as heading, linking to the start of
+// the document. The link to each section is made with fn, if nil "#id" is used. Cross references and
+// citations are indexed with the text they are rendered with, footnotes are not indexed, as they
+// are not part of the section they end up in.
+func NewSearchIndex(doc ast.Node, fn func(id string) string) []SearchEntry {
+	numbering := NewNumbering(doc)
+	entries := []SearchEntry{}
+	cur := SearchEntry{Href: href(fn, "")}
+	if t := findTitle(doc); t != nil && t.TitleData != nil {
+		cur.Heading = t.TitleData.Title
+	}
+	text := &bytes.Buffer{}
+	add := func() {
+		cur.Text = strings.Join(strings.Fields(text.String()), " ")
+		if cur.Heading != "" || cur.Text != "" {
+			entries = append(entries, cur)
+		}
+		text.Reset()
+	}
+
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch node := node.(type) {
+		case *ast.Heading:
+			add()
+			id := headingID(node)
+			cur = SearchEntry{ID: id, Href: href(fn, id), Heading: headingText(node)}
+			return ast.SkipChildren
+		case *ast.Index:
+			term := string(node.Item)
+			if len(node.Subitem) > 0 {
+				term += ", " + string(node.Subitem)
+			}
+			for _, t := range cur.Terms {
+				if t == term {
+					return ast.GoToNext
+				}
+			}
+			cur.Terms = append(cur.Terms, term)
+		case *mast.DocumentIndex, *mast.Bibliography, *mast.TableOfContents:
+			return ast.SkipChildren
+		case *ast.List:
+			if node.IsFootnotesList {
+				return ast.SkipChildren
+			}
+		case *ast.CrossReference:
+			if len(node.GetChildren()) > 0 {
+				return ast.GoToNext
+			}
+			label := numbering.Label(string(node.Destination))
+			if label == "" {
+				label = string(node.Destination)
+			}
+			text.WriteString(label)
+			text.WriteByte(' ')
+		case *ast.Citation:
+			for _, c := range node.Destination {
+				text.Write(c)
+				text.WriteByte(' ')
+			}
+		case *ast.Text, *ast.Code, *ast.CodeBlock:
+			text.Write(node.AsLeaf().Literal)
+			text.WriteByte(' ')
+		}
+		return ast.GoToNext
+	})
+	add()
+	return entries
+}
+
+// SearchIndexJSON returns the search index of doc as JSON, see NewSearchIndex.
+func SearchIndexJSON(doc ast.Node, fn func(id string) string) ([]byte, error) {
+	return json.Marshal(NewSearchIndex(doc, fn))
+}
+
+// SearchScriptTag returns the HTML to include in the head of a page to enable search, script is the
+// name of the search script.
+func SearchScriptTag(script string) []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteString(`  <script src="`)
+	html.EscapeHTML(buf, []byte(script))
+	buf.WriteString("\" defer></script>\n")
+	return buf.Bytes()
+}
+
+// SearchScript returns the search script with the search index in JSON embedded, so it works without
+// a server. The script adds a search box at the start of the page, each result links to its section.
+// The placeholder of the search box is Labels.Search.
+func SearchScript(index []byte) []byte {
+	placeholder, _ := json.Marshal(Labels.Search)
+	return []byte("var mmarkSearchIndex = " + string(index) + ";\nvar mmarkSearchPlaceholder = " +
+		string(placeholder) + ";\n" + searchScript)
+}
+
+const searchScript = `(function() {
+  function search(query) {
+    var words = query.toLowerCase().split(/\s+/).filter(function(w) { return w; });
+    if (!words.length) return [];
+    var results = [];
+    mmarkSearchIndex.forEach(function(e) {
+      var heading = e.heading.toLowerCase(), text = e.text.toLowerCase();
+      var terms = (e.terms || []).join(" ").toLowerCase(), score = 0;
+      for (var i = 0; i < words.length; i++) {
+        var s = (heading.indexOf(words[i]) >= 0 ? 10 : 0) + (terms.indexOf(words[i]) >= 0 ? 5 : 0) +
+          (text.indexOf(words[i]) >= 0 ? 1 : 0);
+        if (!s) return;
+        score += s;
+      }
+      results.push({entry: e, score: score});
+    });
+    results.sort(function(a, b) { return b.score - a.score; });
+    return results.slice(0, 20);
+  }
+
+  function snippet(text, word) {
+    var i = Math.max(0, text.toLowerCase().indexOf(word) - 60);
+    return (i > 0 ? "…" : "") + text.substr(i, 160) + (i + 160 < text.length ? "…" : "");
+  }
+
+  document.addEventListener("DOMContentLoaded", function() {
+    var div = document.createElement("div"), input = document.createElement("input");
+    var list = document.createElement("ol");
+    div.className = "search";
+    input.type = "search";
+    input.placeholder = mmarkSearchPlaceholder;
+    list.className = "search-results";
+    div.appendChild(input);
+    div.appendChild(list);
+    document.body.insertBefore(div, document.body.firstChild);
+
+    input.addEventListener("input", function() {
+      list.innerHTML = "";
+      var first = input.value.trim().split(/\s+/)[0].toLowerCase();
+      search(input.value).forEach(function(r) {
+        var li = document.createElement("li"), a = document.createElement("a"), p = document.createElement("p");
+        a.href = r.entry.href;
+        a.textContent = r.entry.heading || r.entry.href;
+        p.textContent = snippet(r.entry.text, first);
+        li.appendChild(a);
+        li.appendChild(p);
+        list.appendChild(li);
+      });
+    });
+  });
+})();
+`
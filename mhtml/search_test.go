@@ -0,0 +1,83 @@
+package mhtml
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/lang"
+)
+
+func TestSearchIndex(t *testing.T) {
+	in := "Intro text.\n\n# One {#one}\n\nSome *text* and `code` (!item, sub) (!item, sub).\n\n# Two\n\nMore.\n"
+	p := parser.NewWithExtensions(parser.CommonExtensions | parser.AutoHeadingIDs | parser.Mmark)
+	doc := markdown.Parse([]byte(in), p)
+
+	entries := NewSearchIndex(doc, nil)
+	if len(entries) != 3 {
+		t.Fatalf("want 3 entries, got %d: %v", len(entries), entries)
+	}
+	if entries[0].Href != "#" || entries[0].Text != "Intro text." {
+		t.Errorf("want intro entry, got %v", entries[0])
+	}
+	one := entries[1]
+	if one.Href != "#one" || one.Heading != "One" || one.Text != "Some text and code ." {
+		t.Errorf("want entry for section one, got %v", one)
+	}
+	if len(one.Terms) != 1 || one.Terms[0] != "item, sub" {
+		t.Errorf("want a single index term, got %v", one.Terms)
+	}
+	if entries[2].Href != "#two" || entries[2].Text != "More." {
+		t.Errorf("want entry for section two, got %v", entries[2])
+	}
+
+	entries = NewSearchIndex(doc, func(id string) string { return "page.html#" + id })
+	if entries[1].Href != "page.html#one" {
+		t.Errorf("want href in page, got %q", entries[1].Href)
+	}
+
+	index, err := SearchIndexJSON(doc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if script := string(SearchScript(index)); !strings.HasPrefix(script, "var mmarkSearchIndex = [{") {
+		t.Errorf("want embedded index in script, got %q", script[:40])
+	}
+}
+
+func TestSearchFiles(t *testing.T) {
+	index, script := SearchFiles("dir/doc.md")
+	if index != "dir/doc.search.json" || script != "dir/doc.search.js" {
+		t.Errorf("want dir/doc.search.json and dir/doc.search.js, got %q and %q", index, script)
+	}
+	if tag := string(SearchScriptTag(`a"b.js`)); tag != `  <script src="a&quot;b.js" defer></script>`+"\n" {
+		t.Errorf("want escaped script name, got %q", tag)
+	}
+}
+
+func TestSearchIndexReferences(t *testing.T) {
+	in := "# One {#one}\n\nSee (#two) and [@RFC2119].[^1]\n\n# Two {#two}\n\nMore.\n\n[^1]: A footnote.\n"
+	p := parser.NewWithExtensions(parser.CommonExtensions | parser.Footnotes | parser.Mmark)
+	doc := markdown.Parse([]byte(in), p)
+
+	entries := NewSearchIndex(doc, nil)
+	if len(entries) != 2 {
+		t.Fatalf("want 2 entries, got %d: %v", len(entries), entries)
+	}
+	if entries[0].Text != "See Section 2 and RFC2119 ." {
+		t.Errorf("want cross reference and citation in text, got %q", entries[0].Text)
+	}
+	if entries[1].Text != "More." {
+		t.Errorf("want no footnote in text, got %q", entries[1].Text)
+	}
+}
+
+func TestSearchScriptPlaceholder(t *testing.T) {
+	defer func(l lang.Lang) { Labels = l }(Labels)
+	Labels = lang.New("nl")
+
+	if script := string(SearchScript([]byte("[]"))); !strings.Contains(script, `var mmarkSearchPlaceholder = "Zoeken";`) {
+		t.Errorf("want translated placeholder, got %q", script[:80])
+	}
+}
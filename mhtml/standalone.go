@@ -0,0 +1,101 @@
+package mhtml
+
+import (
+	"bytes"
+	"encoding/base64"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+)
+
+// Standalone rewrites the images in doc so the HTML output doesn't depend on external files. SVG
+// images are inlined as <svg> elements, other local images are replaced by data URIs. Remote images
+// are left alone. The files are read with read.
+func Standalone(doc ast.Node, read func(file string) ([]byte, error)) {
+	images := []*ast.Image{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if img, ok := node.(*ast.Image); ok && entering && isLocal(img.Destination) {
+			images = append(images, img)
+		}
+		return ast.GoToNext
+	})
+
+	for _, img := range images {
+		file := string(img.Destination)
+		data, err := read(file)
+		if err != nil {
+			log.Printf("Failure to read image %q: %s", file, err)
+			continue
+		}
+
+		if strings.EqualFold(filepath.Ext(file), ".svg") {
+			if svg := svgElement(data); svg != nil {
+				replaceNode(img, &ast.HTMLSpan{Leaf: ast.Leaf{Literal: svg}})
+				continue
+			}
+			log.Printf("No <svg> element found in %q, using a data URI", file)
+		}
+		img.Destination = DataURI(file, data)
+	}
+}
+
+// StyleSheet returns css wrapped in a <style> element, suitable for inclusion in
+// html.RendererOptions.Head.
+func StyleSheet(css []byte) []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteString("  <style>\n")
+	buf.Write(css)
+	if len(css) > 0 && css[len(css)-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+	buf.WriteString("  </style>\n")
+	return buf.Bytes()
+}
+
+// DataURI returns the base64 encoded data URI for data, the media type is derived from the extension
+// of file, or from the contents of data if the extension is not known.
+func DataURI(file string, data []byte) []byte {
+	typ := mime.TypeByExtension(filepath.Ext(file))
+	if typ == "" {
+		typ = http.DetectContentType(data)
+	}
+	return []byte("data:" + typ + ";base64," + base64.StdEncoding.EncodeToString(data))
+}
+
+// isLocal returns true if dest refers to a local file.
+func isLocal(dest []byte) bool {
+	d := string(dest)
+	if d == "" || strings.HasPrefix(d, "//") || strings.HasPrefix(d, "data:") {
+		return false
+	}
+	return !strings.Contains(d, "://")
+}
+
+// svgElement returns the <svg> element from data, without any XML declaration, doctype or comments
+// that precede it.
+func svgElement(data []byte) []byte {
+	i := bytes.Index(data, []byte("<svg"))
+	if i < 0 {
+		return nil
+	}
+	return bytes.TrimSpace(data[i:])
+}
+
+// replaceNode replaces old with new in the tree.
+func replaceNode(old, new ast.Node) {
+	parent := old.GetParent()
+	children := parent.GetChildren()
+	for i, child := range children {
+		if child == old {
+			children[i] = new
+			break
+		}
+	}
+	parent.SetChildren(children)
+	new.SetParent(parent)
+	old.SetParent(nil)
+}
@@ -0,0 +1,48 @@
+package mhtml
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+func TestStandalone(t *testing.T) {
+	files := map[string][]byte{
+		"a.svg": []byte("<?xml version=\"1.0\"?>\n<svg><rect/></svg>\n"),
+		"b.gif": []byte("GIF89a"),
+	}
+	read := func(file string) ([]byte, error) {
+		if data, ok := files[file]; ok {
+			return data, nil
+		}
+		return nil, errors.New("not found")
+	}
+
+	doc := markdown.Parse([]byte("![a](a.svg) ![b](b.gif) ![c](https://example.org/c.png)\n"), parser.New())
+	Standalone(doc, read)
+
+	got := []string{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		switch node := node.(type) {
+		case *ast.HTMLSpan:
+			got = append(got, string(node.Literal))
+		case *ast.Image:
+			if entering {
+				got = append(got, string(node.Destination))
+			}
+		}
+		return ast.GoToNext
+	})
+	want := []string{"<svg><rect/></svg>", "data:image/gif;base64,R0lGODlh", "https://example.org/c.png"}
+	if len(got) != len(want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("want %q, got %q", want[i], got[i])
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package mhtml
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/mmarkdown/mmark/mast"
+)
+
+// tableCell opens a table cell with a rowspan or width attribute, see mparser.TableSpans, which the
+// HTML renderer doesn't output. It returns false for the other cells, and when closing the cell, to
+// leave those to the HTML renderer.
+func tableCell(w io.Writer, cell *ast.TableCell, entering bool) bool {
+	rowspan, width := mast.Attribute(cell, "rowspan"), mast.Attribute(cell, "width")
+	if !entering || (rowspan == nil && width == nil) {
+		return false
+	}
+
+	tag := "<td"
+	if cell.IsHeader {
+		tag = "<th"
+	}
+	if ast.GetPrevNode(cell) == nil {
+		io.WriteString(w, "\n")
+	}
+	io.WriteString(w, tag)
+	if align := cell.Align.String(); align != "" {
+		io.WriteString(w, ` align="`+align+`"`)
+	}
+	if cell.ColSpan > 1 {
+		io.WriteString(w, ` colspan="`+strconv.Itoa(cell.ColSpan)+`"`)
+	}
+	if rowspan != nil {
+		io.WriteString(w, ` rowspan="`)
+		html.EscapeHTML(w, rowspan)
+		io.WriteString(w, `"`)
+	}
+	if width != nil {
+		io.WriteString(w, ` style="width: `)
+		html.EscapeHTML(w, width)
+		io.WriteString(w, `"`)
+	}
+	io.WriteString(w, ">")
+	return true
+}
@@ -0,0 +1,272 @@
+package mhtml
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/mmarkdown/mmark/mast"
+)
+
+// title outputs the title page from the TOML title block.
+func title(w io.Writer, t *mast.Title, entering bool) {
+	if !entering {
+		return
+	}
+	d := t.TitleData
+	if d == nil {
+		return
+	}
+
+	io.WriteString(w, "<header class=\"title\">\n")
+	io.WriteString(w, "<dl class=\"title-meta\">\n")
+
+	titleMeta(w, "workgroup", Labels.Workgroup, d.Workgroup)
+	titleMeta(w, "series-info", d.SeriesInfo.Name, d.SeriesInfo.Value)
+	titleMeta(w, "updates", Labels.Updates, intSliceToString(d.Updates))
+	titleMeta(w, "obsoletes", Labels.Obsoletes, intSliceToString(d.Obsoletes))
+	if !d.Date.IsZero() {
+		titleLabel(w, Labels.Published)
+		io.WriteString(w, `<dd class="date"><time datetime="`+d.Date.String()+`">`)
+		io.WriteString(w, dateToText(d.Date))
+		io.WriteString(w, "</time></dd>\n")
+	}
+	status := Labels.Category
+	if d.SeriesInfo.Name == "Internet-Draft" {
+		status = Labels.IntendedStatus
+	}
+	titleMeta(w, "status", status, Labels.Status(d.SeriesInfo.Status))
+
+	if len(d.Author) > 0 {
+		titleLabel(w, Labels.Authors)
+		io.WriteString(w, "<dd class=\"authors\">\n")
+		for _, a := range d.Author {
+			io.WriteString(w, `<div class="author">`)
+			io.WriteString(w, `<span class="author-name">`)
+			html.EscapeHTML(w, []byte(a.Fullname))
+			io.WriteString(w, "</span>")
+			if a.Organization != "" {
+				io.WriteString(w, ` <span class="author-organization">`)
+				html.EscapeHTML(w, []byte(a.Organization))
+				io.WriteString(w, "</span>")
+			}
+			io.WriteString(w, "</div>\n")
+		}
+		io.WriteString(w, "</dd>\n")
+	}
+	io.WriteString(w, "</dl>\n")
+
+	io.WriteString(w, `<h1 class="title">`)
+	html.EscapeHTML(w, []byte(d.Title))
+	io.WriteString(w, "</h1>\n")
+	io.WriteString(w, "</header>\n")
+}
+
+// titleMeta outputs a single dt/dd pair of the title page, if value is not empty.
+func titleMeta(w io.Writer, class, name, value string) {
+	if value == "" || name == "" {
+		return
+	}
+	titleLabel(w, name)
+	io.WriteString(w, `<dd class="`+class+`">`)
+	html.EscapeHTML(w, []byte(value))
+	io.WriteString(w, "</dd>\n")
+}
+
+// titleLabel outputs the dt element with name for the title page.
+func titleLabel(w io.Writer, name string) {
+	io.WriteString(w, "<dt>")
+	html.EscapeHTML(w, []byte(name))
+	io.WriteString(w, ":</dt>\n")
+}
+
+// authorsAddresses outputs the "Authors' Addresses" section from the TOML title block.
+func authorsAddresses(w io.Writer, t *mast.Title) {
+	d := t.TitleData
+	if d == nil || len(d.Author) == 0 {
+		return
+	}
+
+	heading := Labels.AuthorsAddresses
+	if len(d.Author) == 1 {
+		heading = Labels.AuthorsAddress
+	}
+	io.WriteString(w, "<h1 id=\"authors-addresses\">")
+	html.EscapeHTML(w, []byte(heading))
+	io.WriteString(w, "</h1>\n")
+	io.WriteString(w, "<div class=\"authors-addresses\">\n")
+	for _, a := range d.Author {
+		io.WriteString(w, "<address class=\"author\">\n")
+		authorLine(w, "author-name", a.Fullname)
+		authorLine(w, "author-organization", a.Organization)
+
+		p := a.Address.Postal
+		authorLine(w, "street", p.Street)
+		authorLines(w, "street", p.Streets)
+		authorLine(w, "city", p.City)
+		authorLines(w, "city", p.Cities)
+		authorLine(w, "region", p.Region)
+		authorLines(w, "region", p.Regions)
+		authorLine(w, "code", p.Code)
+		authorLines(w, "code", p.Codes)
+		authorLine(w, "country", p.Country)
+		authorLines(w, "country", p.Countries)
+		authorLines(w, "postal-line", p.PostalLine)
+
+		if a.Address.Phone != "" {
+			io.WriteString(w, `<div class="phone">`)
+			html.EscapeHTML(w, []byte(Labels.Phone+": "))
+			html.EscapeHTML(w, []byte(a.Address.Phone))
+			io.WriteString(w, "</div>\n")
+		}
+		if a.Address.Email != "" {
+			io.WriteString(w, `<div class="email">`)
+			html.EscapeHTML(w, []byte(Labels.Email+": "))
+			io.WriteString(w, `<a href="mailto:`)
+			html.EscapeHTML(w, []byte(a.Address.Email))
+			io.WriteString(w, `">`)
+			html.EscapeHTML(w, []byte(a.Address.Email))
+			io.WriteString(w, "</a></div>\n")
+		}
+		if a.Address.URI != "" {
+			io.WriteString(w, `<div class="uri">`)
+			html.EscapeHTML(w, []byte(Labels.URI+": "))
+			io.WriteString(w, `<a href="`)
+			html.EscapeHTML(w, []byte(a.Address.URI))
+			io.WriteString(w, `">`)
+			html.EscapeHTML(w, []byte(a.Address.URI))
+			io.WriteString(w, "</a></div>\n")
+		}
+		io.WriteString(w, "</address>\n")
+	}
+	io.WriteString(w, "</div>\n")
+}
+
+func authorLine(w io.Writer, class, value string) {
+	if value == "" {
+		return
+	}
+	io.WriteString(w, `<div class="`+class+`">`)
+	html.EscapeHTML(w, []byte(value))
+	io.WriteString(w, "</div>\n")
+}
+
+func authorLines(w io.Writer, class string, values []string) {
+	for _, v := range values {
+		authorLine(w, class, v)
+	}
+}
+
+// TitleMeta returns the HTML <meta> tags derived from the title block and the abstract
+// in doc. The returned bytes are suitable for inclusion in html.RendererOptions.Head.
+func TitleMeta(doc ast.Node) []byte {
+	t := findTitle(doc)
+	if t == nil || t.TitleData == nil {
+		return nil
+	}
+	d := t.TitleData
+
+	buf := &bytes.Buffer{}
+	authors := []string{}
+	for _, a := range d.Author {
+		if a.Fullname != "" {
+			authors = append(authors, a.Fullname)
+		}
+	}
+	meta(buf, "author", strings.Join(authors, ", "))
+	meta(buf, "keywords", strings.Join(d.Keyword, ", "))
+	if !d.Date.IsZero() {
+		meta(buf, "date", d.Date.String())
+	}
+	meta(buf, "description", abstractText(doc))
+
+	return buf.Bytes()
+}
+
+func meta(w io.Writer, name, content string) {
+	if content == "" {
+		return
+	}
+	io.WriteString(w, `  <meta name="`+name+`" content="`)
+	html.EscapeHTML(w, []byte(content))
+	io.WriteString(w, "\">\n")
+}
+
+// findTitle returns the first title block in doc, or nil if there is none.
+func findTitle(doc ast.Node) *mast.Title {
+	var t *mast.Title
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if x, ok := node.(*mast.Title); ok {
+			t = x
+			return ast.Terminate
+		}
+		return ast.GoToNext
+	})
+	return t
+}
+
+// abstractText returns the text of the abstract, stripped of all markup. The abstract
+// consists of the nodes following the abstract special heading, up until the next heading.
+func abstractText(doc ast.Node) string {
+	var abstract ast.Node
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if h, ok := node.(*ast.Heading); ok && h.IsSpecial && isAbstract(h.Literal) {
+			abstract = h
+			return ast.Terminate
+		}
+		return ast.GoToNext
+	})
+	if abstract == nil {
+		return ""
+	}
+
+	buf := &bytes.Buffer{}
+	for next := ast.GetNextNode(abstract); next != nil; next = ast.GetNextNode(next) {
+		if _, ok := next.(*ast.Heading); ok {
+			break
+		}
+		if _, ok := next.(*ast.DocumentMatter); ok {
+			break
+		}
+		ast.WalkFunc(next, func(node ast.Node, entering bool) ast.WalkStatus {
+			switch node.(type) {
+			case *ast.Text, *ast.Code:
+				buf.Write(node.AsLeaf().Literal)
+			case *ast.Softbreak, *ast.Hardbreak:
+				buf.WriteByte(' ')
+			case *ast.Paragraph:
+				if !entering {
+					buf.WriteByte(' ')
+				}
+			}
+			return ast.GoToNext
+		})
+	}
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
+
+// dateToText returns the date as shown on the title page, only the specified components are included.
+func dateToText(d mast.Date) string {
+	switch {
+	case d.Month == 0:
+		return strconv.Itoa(d.Year)
+	case d.Day == 0:
+		return Labels.Month(int(d.Month)) + " " + strconv.Itoa(d.Year)
+	}
+	return strconv.Itoa(d.Day) + " " + Labels.Month(int(d.Month)) + " " + strconv.Itoa(d.Year)
+}
+
+func isAbstract(word []byte) bool {
+	return strings.EqualFold(string(word), "abstract")
+}
+
+func intSliceToString(is []int) string {
+	s := []string{}
+	for i := range is {
+		s = append(s, strconv.Itoa(is[i]))
+	}
+	return strings.Join(s, ", ")
+}
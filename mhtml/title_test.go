@@ -0,0 +1,102 @@
+package mhtml
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/mast"
+)
+
+func titleBlock() *mast.Title {
+	t := mast.NewTitle()
+	t.Title = "Fish & <Chips>"
+	t.SeriesInfo = mast.SeriesInfo{Name: "Internet-Draft", Value: "draft-fish-chips-00", Status: "informational"}
+	t.Workgroup = "Food <Area>"
+	t.Updates = []int{1034, 1035}
+	t.Date = mast.Date{Year: 2018, Month: time.July}
+	t.Author = []mast.Author{
+		{
+			Fullname:     "Alice \"A\" Smith",
+			Organization: "Fish & Co",
+			Address: mast.Address{
+				Email:  "alice@example.org",
+				URI:    "https://example.org/?a=1&b=2",
+				Postal: mast.AddressPostal{Street: "1 <Main> St", City: "Amsterdam"},
+			},
+		},
+	}
+	return t
+}
+
+func TestTitle(t *testing.T) {
+	buf := &bytes.Buffer{}
+	title(buf, titleBlock(), true)
+	out := buf.String()
+	for _, want := range []string{
+		`<dt>Workgroup:</dt>` + "\n" + `<dd class="workgroup">Food &lt;Area&gt;</dd>`,
+		`<dd class="series-info">draft-fish-chips-00</dd>`,
+		`<dd class="updates">1034, 1035</dd>`,
+		`<dd class="date"><time datetime="2018-07">July 2018</time></dd>`,
+		`<dt>Intended Status:</dt>` + "\n" + `<dd class="status">Informational</dd>`,
+		`<span class="author-name">Alice &quot;A&quot; Smith</span> <span class="author-organization">Fish &amp; Co</span>`,
+		`<h1 class="title">Fish &amp; &lt;Chips&gt;</h1>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("want %q in %q", want, out)
+		}
+	}
+	if strings.Contains(out, "obsoletes") {
+		t.Errorf("want no obsoletes in %q", out)
+	}
+
+	buf.Reset()
+	title(buf, titleBlock(), false)
+	if buf.Len() != 0 {
+		t.Errorf("want no output when exiting, got %q", buf.String())
+	}
+}
+
+func TestAuthorsAddresses(t *testing.T) {
+	buf := &bytes.Buffer{}
+	authorsAddresses(buf, titleBlock())
+	out := buf.String()
+	for _, want := range []string{
+		`<h1 id="authors-addresses">Author's Address</h1>`,
+		`<div class="author-name">Alice &quot;A&quot; Smith</div>`,
+		`<div class="street">1 &lt;Main&gt; St</div>`,
+		`<div class="city">Amsterdam</div>`,
+		`<div class="email">Email: <a href="mailto:alice@example.org">alice@example.org</a></div>`,
+		`<div class="uri">URI: <a href="https://example.org/?a=1&amp;b=2">https://example.org/?a=1&amp;b=2</a></div>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("want %q in %q", want, out)
+		}
+	}
+	if strings.Contains(out, "phone") {
+		t.Errorf("want no phone in %q", out)
+	}
+}
+
+func TestTitleMeta(t *testing.T) {
+	in := ".# Abstract\n\nThe <b>abstract</b> & more.\n\n# Introduction\n\nText.\n"
+	p := parser.NewWithExtensions(parser.CommonExtensions | parser.Mmark)
+	doc := markdown.Parse([]byte(in), p)
+	tb := titleBlock()
+	doc.SetChildren(append([]ast.Node{tb}, doc.GetChildren()...))
+
+	out := string(TitleMeta(doc))
+	for _, want := range []string{
+		`<meta name="author" content="Alice &quot;A&quot; Smith">`,
+		`<meta name="date" content="2018-07">`,
+		`<meta name="description" content="The abstract &amp; more.">`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("want %q in %q", want, out)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package mhtml
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/mmarkdown/mmark/mast"
+)
+
+// TOCDepth is the default depth of the table of contents.
+const TOCDepth = 3
+
+// TOCSidebarCSS is the CSS to include in the head of a complete page when the table of contents is
+// rendered as a sidebar.
+const TOCSidebarCSS = `  <style>
+    nav.toc-sidebar { position: fixed; top: 0; bottom: 0; left: 0; width: 18em; overflow-y: auto; padding: 0 1em; }
+    body { margin-left: 21em; }
+  </style>
+`
+
+// TOC renders the table of contents of the document in place of a mast.TableOfContents node.
+type TOC struct {
+	Depth   int  // Maximum heading level to include, 0 includes all levels.
+	Sidebar bool // Render the table of contents as a sidebar, see TOCSidebarCSS.
+
+	// Href returns the link target for id, if nil "#id" is used, with the HeadingIDPrefix and
+	// HeadingIDSuffix added to id.
+	Href func(id string) string
+	// HeadingIDPrefix and HeadingIDSuffix are added to the IDs of the headings, as the HTML renderer
+	// options of the same name do.
+	HeadingIDPrefix string
+	HeadingIDSuffix string
+}
+
+// RenderNode renders the table of contents, it can be registered as a mast.RenderFunc.
+func (t TOC) RenderNode(w io.Writer, node ast.Node, entering bool) ast.WalkStatus {
+	if !entering {
+		return ast.GoToNext
+	}
+	doc := node
+	for doc.GetParent() != nil {
+		doc = doc.GetParent()
+	}
+	entries := tocEntries(doc, t.Depth)
+	if len(entries) == 0 {
+		return ast.GoToNext
+	}
+
+	class := "toc"
+	if t.Sidebar {
+		class += " toc-sidebar"
+	}
+	io.WriteString(w, "\n<nav class=\""+class+"\">\n")
+	io.WriteString(w, "<h1 id=\"table-of-contents\" class=\"special\">")
+	html.EscapeHTML(w, []byte(Labels.TableOfContents))
+	io.WriteString(w, "</h1>\n")
+
+	depth := 0
+	for _, e := range entries {
+		level := e.level
+		if level > depth+1 {
+			level = depth + 1
+		}
+		if level > depth {
+			io.WriteString(w, "<ul>\n")
+			depth = level
+		} else {
+			io.WriteString(w, "</li>\n")
+			for ; depth > level; depth-- {
+				io.WriteString(w, "</ul>\n</li>\n")
+			}
+		}
+		target := "#" + t.HeadingIDPrefix + e.id + t.HeadingIDSuffix
+		if t.Href != nil {
+			target = t.Href(e.id)
+		}
+		io.WriteString(w, `<li><a href="`)
+		html.EscapeHTML(w, []byte(target))
+		io.WriteString(w, `">`)
+		if e.number != "" {
+			io.WriteString(w, `<span class="toc-number">`+e.number+".</span> ")
+		}
+		html.EscapeHTML(w, []byte(e.text))
+		io.WriteString(w, "</a>\n")
+	}
+	io.WriteString(w, "</li>\n")
+	for ; depth > 1; depth-- {
+		io.WriteString(w, "</ul>\n</li>\n")
+	}
+	io.WriteString(w, "</ul>\n</nav>\n")
+
+	return ast.GoToNext
+}
+
+// href returns the link target for id, using fn if it is not nil.
+func href(fn func(id string) string, id string) string {
+	if fn != nil {
+		return fn(id)
+	}
+	return "#" + id
+}
+
+type tocEntry struct {
+	level  int
+	number string
+	id     string
+	text   string
+}
+
+// tocEntries returns the entries for the table of contents, only headings up to and including
+// level depth are included. Special headings, like the abstract, are left out.
+func tocEntries(doc ast.Node, depth int) []tocEntry {
+	numbers := headingNumbers(doc)
+	entries := []tocEntry{}
+	for _, child := range doc.GetChildren() {
+		h, ok := child.(*ast.Heading)
+		if !ok || h.IsTitleblock || h.IsSpecial {
+			continue
+		}
+		if depth > 0 && h.Level > depth {
+			continue
+		}
+		id := headingID(h)
+		if id == "" {
+			continue
+		}
+		entries = append(entries, tocEntry{level: h.Level, number: numbers[h], id: id, text: headingText(h)})
+	}
+	return entries
+}
+
+// headingNumbers returns the section numbers of the top level headings in doc. Headings in the main
+// matter are numbered 1, 1.1, etc, headings in the back matter A, A.1, etc. Headings in the front
+// matter and special headings are not numbered. When there are no document matter divisions all
+// headings are numbered as if they are in the main matter.
+func headingNumbers(doc ast.Node) map[*ast.Heading]string {
+	numbers := map[*ast.Heading]string{}
+	matter := ast.DocumentMatterMain
+	counters := []int{}
+	for _, child := range doc.GetChildren() {
+		switch node := child.(type) {
+		case *ast.DocumentMatter:
+			matter = node.Matter
+			counters = counters[:0]
+		case *ast.Heading:
+			if node.IsTitleblock || node.IsSpecial || matter == ast.DocumentMatterFront {
+				continue
+			}
+			for len(counters) < node.Level {
+				counters = append(counters, 0)
+			}
+			counters = counters[:node.Level]
+			counters[node.Level-1]++
+
+			parts := make([]string, len(counters))
+			for i, c := range counters {
+				parts[i] = strconv.Itoa(c)
+			}
+			if matter == ast.DocumentMatterBack {
+				parts[0] = appendixLetter(counters[0])
+			}
+			numbers[node] = strings.Join(parts, ".")
+		}
+	}
+	return numbers
+}
+
+// appendixLetter returns the letter(s) used to number appendix n: A, B, ..., Z, AA, AB, ...
+func appendixLetter(n int) string {
+	s := ""
+	for n > 0 {
+		n--
+		s = string(rune('A'+n%26)) + s
+		n /= 26
+	}
+	return s
+}
+
+// headingText returns the text of heading h, stripped of all markup.
+func headingText(h *ast.Heading) string {
+	buf := &bytes.Buffer{}
+	ast.WalkFunc(h, func(node ast.Node, entering bool) ast.WalkStatus {
+		switch node.(type) {
+		case *ast.Text, *ast.Code:
+			buf.Write(node.AsLeaf().Literal)
+		}
+		return ast.GoToNext
+	})
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
+
+// AddTOC adds a mast.TableOfContents node to doc, if it doesn't have one already. It is added at
+// the start of the main matter, or after the title block if there is no main matter.
+func AddTOC(doc ast.Node) {
+	if hasTOC(doc) {
+		return
+	}
+
+	var after ast.Node
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		switch node := node.(type) {
+		case *mast.Title:
+			if after == nil {
+				after = node
+			}
+		case *ast.DocumentMatter:
+			if node.Matter == ast.DocumentMatterMain {
+				after = node
+			}
+		}
+		return ast.GoToNext
+	})
+	insertAfter(doc, after, &mast.TableOfContents{})
+}
+
+// insertAfter inserts node as a child of parent, after the child after. If after is nil or not a child
+// of parent, node is inserted as the first child.
+func insertAfter(parent, after, node ast.Node) {
+	node.SetParent(parent)
+	children := parent.GetChildren()
+	i := 0
+	for j, child := range children {
+		if child == after {
+			i = j + 1
+			break
+		}
+	}
+	children = append(children, nil)
+	copy(children[i+1:], children[i:])
+	children[i] = node
+	parent.SetChildren(children)
+}
@@ -0,0 +1,80 @@
+package mhtml
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/mast"
+)
+
+func TestHeadingNumbers(t *testing.T) {
+	in := ".# Abstract\n\n{mainmatter}\n\n# One\n\n## One.One\n\n# Two\n\n{backmatter}\n\n# App\n\n## App.One\n"
+	p := parser.NewWithExtensions(parser.CommonExtensions | parser.AutoHeadingIDs | parser.Mmark)
+	doc := markdown.Parse([]byte(in), p)
+
+	numbers := headingNumbers(doc)
+	got := []string{}
+	for _, child := range doc.GetChildren() {
+		if h, ok := child.(*ast.Heading); ok {
+			got = append(got, numbers[h])
+		}
+	}
+	want := []string{"", "1", "1.1", "2", "A", "A.1"}
+	if len(got) != len(want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("want %q, got %q, for heading %d", want[i], got[i], i)
+		}
+	}
+}
+
+func TestAppendixLetter(t *testing.T) {
+	tests := map[int]string{1: "A", 26: "Z", 27: "AA", 28: "AB"}
+	for n, want := range tests {
+		if got := appendixLetter(n); got != want {
+			t.Errorf("want %q, got %q, for %d", want, got, n)
+		}
+	}
+}
+
+func TestTOCEntries(t *testing.T) {
+	in := ".# Abstract\n\n{mainmatter}\n\n# One\n\n## One.One\n\n### Deep\n\n# Two\n"
+	p := parser.NewWithExtensions(parser.CommonExtensions | parser.AutoHeadingIDs | parser.Mmark)
+	doc := markdown.Parse([]byte(in), p)
+
+	got := []string{}
+	for _, e := range tocEntries(doc, 2) {
+		got = append(got, e.number+" "+e.text)
+	}
+	want := []string{"1 One", "1.1 One.One", "2 Two"}
+	if len(got) != len(want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("want %q, got %q, for entry %d", want[i], got[i], i)
+		}
+	}
+}
+
+func TestTOCHeadingIDPrefix(t *testing.T) {
+	in := "# One\n\n# Two\n"
+	p := parser.NewWithExtensions(parser.CommonExtensions | parser.AutoHeadingIDs | parser.Mmark)
+	doc := markdown.Parse([]byte(in), p)
+	toc := &mast.TableOfContents{}
+	ast.AppendChild(doc, toc)
+
+	buf := &bytes.Buffer{}
+	TOC{HeadingIDPrefix: "pre-", HeadingIDSuffix: "-suf"}.RenderNode(buf, toc, true)
+	for _, want := range []string{`href="#pre-one-suf"`, `href="#pre-two-suf"`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("want %q in %q", want, buf.String())
+		}
+	}
+}
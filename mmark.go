@@ -6,34 +6,56 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/gomarkdown/markdown"
 	"github.com/gomarkdown/markdown/ast"
 	"github.com/gomarkdown/markdown/html"
 	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/lang"
 	"github.com/mmarkdown/mmark/mast"
 	"github.com/mmarkdown/mmark/mhtml"
 	"github.com/mmarkdown/mmark/mparser"
+	"github.com/mmarkdown/mmark/transform"
 	"github.com/mmarkdown/mmark/xml"
 	"github.com/mmarkdown/mmark/xml2"
 )
 
 var (
-	flagCSS      = flag.String("css", "", "link to a CSS stylesheet (only used with -html)")
-	flagHead     = flag.String("head", "", "link to HTML to be included in head (only used with -html)")
-	flagAst      = flag.Bool("ast", false, "print abstract syntax tree and exit")
-	flagBib      = flag.Bool("bibliography", true, "generate a bibliography section after the back matter")
-	flagFragment = flag.Bool("fragment", false, "don't create a full document")
-	flagHTML     = flag.Bool("html", false, "create HTML output")
-	flagIndex    = flag.Bool("index", true, "generate an index at the end of the document")
-	flagTwo      = flag.Bool("2", false, "generate RFC 7749 XML")
-	flagUnsafe   = flag.Bool("unsafe", false, "allow unsafe includes")
-	flagVersion  = flag.Bool("version", false, "show mmark version")
+	flagCSS        = flag.String("css", "", "link to a CSS stylesheet (only used with -html)")
+	flagDisable    = flag.String("disable", "", "comma separated list of transformation passes to disable")
+	flagEnable     = flag.String("enable", "", "comma separated list of transformation passes to enable")
+	flagHead       = flag.String("head", "", "link to HTML to be included in head (only used with -html)")
+	flagAst        = flag.Bool("ast", false, "print abstract syntax tree and exit")
+	flagBib        = flag.Bool("bibliography", true, "generate a bibliography section after the back matter")
+	flagBCP14Link  = flag.String("bcp14-link", "", "link the BCP 14 keywords to this URL or #anchor (only used with -html)")
+	flagBook       = flag.String("book", "", "write multi-page HTML to this directory, split by chapter (only used with -html)")
+	flagBookSplit  = flag.String("book-split", "chapter", "split the book at each \"chapter\" or document \"matter\" division")
+	flagCiteStyle  = flag.String("citation-style", "anchor", "label citations with the \"anchor\", \"numeric\" or \"author-year\" (only used with -html)")
+	flagFootnotes  = flag.String("footnotes", "none", "render footnotes in XML as \"notes\" in a section, \"cref\" comments or \"inline\" text, or discard them (\"none\")")
+	flagFragment   = flag.Bool("fragment", false, "don't create a full document")
+	flagHTML       = flag.Bool("html", false, "create HTML output")
+	flagHighlight  = flag.String("highlight", "", "highlight code blocks with style \"light\", \"dark\" or \"classes\" (only used with -html)")
+	flagSearch     = flag.Bool("search", false, "write a search index and script next to the output (only used with -html)")
+	flagStandalone = flag.Bool("standalone", false, "inline the stylesheet and local images (only used with -html)")
+	flagIndex      = flag.Bool("index", true, "generate an index at the end of the document")
+	flagLabels     = flag.String("labels", "", "TOML file that overrides the generated labels")
+	flagNoComments = flag.Bool("no-comments", false, "remove the editorial comments, i.e. \"[[ alice: fix this ]]\"")
+	flagLang       = flag.String("lang", "", "language of the generated labels, overrides lang in the title block")
+	flagTwo        = flag.Bool("2", false, "generate RFC 7749 XML")
+	flagUnknown    = flag.String("unknown", "children", "render the \"children\" of unknown nodes, \"skip\" them or stop with an \"error\"")
+	flagUnsafe     = flag.Bool("unsafe", false, "allow unsafe includes")
+	flagVersion    = flag.Bool("version", false, "show mmark version")
+	flagToc        = flag.Bool("toc", false, "generate a table of contents (only used with -html)")
+	flagTocDepth   = flag.Int("toc-depth", mhtml.TOCDepth, "maximum heading level in the table of contents")
+	flagTocSidebar = flag.Bool("toc-sidebar", false, "render the table of contents as a sidebar")
 )
 
 func main() {
 	flag.Usage = func() {
 		fmt.Fprintf(flag.CommandLine.Output(), "SYNOPSIS: %s [OPTIONS] %s\n", os.Args[0], "[FILE...]")
+		fmt.Fprintf(flag.CommandLine.Output(), "          %s draft expire|bump|check %s\n", os.Args[0], "FILE...")
 		fmt.Println("\nOPTIONS:")
 		flag.PrintDefaults()
 	}
@@ -47,6 +69,25 @@ func main() {
 		fmt.Println(Version)
 		os.Exit(0)
 	}
+	if args[0] == "draft" {
+		os.Exit(draftMain(args[1:]))
+	}
+
+	if !*flagBib {
+		transform.Default.Disable("bibliography")
+	}
+	if !*flagIndex {
+		transform.Default.Disable("index")
+	}
+	if *flagNoComments {
+		transform.Default.Enable("no-comments")
+	}
+	if err := transformFlags(transform.Default, *flagEnable, *flagDisable); err != nil {
+		log.Fatal(err)
+	}
+	if *flagSearch && *flagFragment {
+		log.Fatal("Search needs a full HTML document, -search can't be used with -fragment")
+	}
 
 	for _, fileName := range args {
 		var (
@@ -74,18 +115,30 @@ func main() {
 		}
 
 		documentTitle := "" // hack to get document title from toml title block and then set it here.
+		documentLang := *flagLang
+
+		footnotes, ok := xml.FootnoteStyles[*flagFootnotes]
+		if !ok {
+			log.Fatalf("Unknown footnote style %q", *flagFootnotes)
+		}
 
 		p := parser.NewWithExtensions(Extensions)
 		parserFlags := parser.FlagsNone
-		if !*flagHTML {
-			// both xml formats don't deal with footnotes well.
+		if !*flagHTML && footnotes == xml.FootnoteNone {
+			// both xml formats discard footnotes unless -footnotes says otherwise.
 			parserFlags |= parser.SkipFootnoteList
 		}
 		p.Opts = parser.ParserOptions{
 			ParserHook: func(data []byte) (ast.Node, []byte, int) {
+				if node, data, consumed := init.CodeIncludeHook(data); consumed > 0 {
+					return node, data, consumed
+				}
 				node, data, consumed := mparser.Hook(data)
 				if t, ok := node.(*mast.Title); ok {
 					documentTitle = t.TitleData.Title
+					if *flagLang == "" {
+						documentLang = t.TitleData.Language
+					}
 				}
 				return node, data, consumed
 			},
@@ -94,12 +147,8 @@ func main() {
 		}
 
 		doc := markdown.Parse(d, p)
-		if *flagBib {
-			addBibliography(doc)
-		}
-		if *flagIndex {
-			addIndex(doc)
-		}
+		mparser.Syntax(doc)
+		transform.Default.Run(doc)
 
 		if *flagAst {
 			ast.Print(os.Stdout, doc)
@@ -107,13 +156,56 @@ func main() {
 			return
 		}
 
+		language := lang.New(documentLang)
+		if *flagLabels != "" {
+			if err := language.Load(*flagLabels); err != nil {
+				log.Fatalf("Couldn't load labels from %q: %s", *flagLabels, err)
+			}
+		}
+
 		var renderer markdown.Renderer
 
+		unknown, ok := mast.UnknownNodes[*flagUnknown]
+		if !ok {
+			log.Fatalf("Unknown value %q for -unknown", *flagUnknown)
+		}
+		var renderErr func() error // returns the error that stopped the rendering
+
 		if *flagHTML {
+			// TODO(miek): make this an option.
+			comments := [][]byte{[]byte("//"), []byte("#")}
+			mhtml.Labels = language
+			mhtml.BCP14Link = *flagBCP14Link
+			indexReturn := &strings.Builder{}
+			html.EscapeHTML(indexReturn, []byte(language.IndexReturn))
+			mhtml.IndexReturnLinkContents = "<sup>[" + indexReturn.String() + "]</sup>"
+			if *flagToc {
+				mhtml.AddTOC(doc)
+			}
+			toc := mhtml.TOC{Depth: *flagTocDepth, Sidebar: *flagTocSidebar}
+			renderers := mast.Renderers{}
+			renderers.Register(&mast.TableOfContents{}, toc.RenderNode)
+			callouts := mhtml.NewCallouts(doc, comments)
+			renderers.Register(&ast.Callout{}, callouts.RenderNode)
+			if *flagHighlight != "" {
+				renderers.Register(&ast.CodeBlock{}, mhtml.Highlighter{Comments: comments, Callouts: callouts}.RenderNode)
+			} else {
+				renderers.Register(&ast.CodeBlock{}, callouts.RenderNode)
+			}
+			style, ok := mhtml.CitationStyles[*flagCiteStyle]
+			if !ok {
+				log.Fatalf("Unknown citation style %q", *flagCiteStyle)
+			}
+			bib := mhtml.NewBibliography(doc, style)
+			renderers.Register(&mast.Bibliography{}, bib.RenderNode)
+			renderers.Register(&mast.BibliographyItem{}, bib.RenderNode)
+			renderers.Register(&ast.Citation{}, bib.RenderNode)
+
+			hook := mhtml.NewRenderHook(renderers, unknown)
+			renderErr = hook.Err
 			opts := html.RendererOptions{
-				// TODO(miek): make this an option.
-				Comments:       [][]byte{[]byte("//"), []byte("#")},
-				RenderNodeHook: mhtml.RenderHook,
+				Comments:       comments,
+				RenderNodeHook: hook.RenderNode,
 				Flags:          html.CommonFlags | html.FootnoteNoHRTag | html.FootnoteReturnLinks,
 				Generator:      `  <meta name="GENERATOR" content="github.com/mmarkdown/mmark Mmark Markdown Processor - mmark.nl`,
 			}
@@ -121,70 +213,182 @@ func main() {
 				opts.Flags |= html.CompletePage
 			}
 			opts.CSS = *flagCSS
+			if *flagStandalone {
+				mhtml.Standalone(doc, init.ReadFile)
+			}
+			opts.Head = mhtml.TitleMeta(doc)
+			if toc.Sidebar && !*flagFragment {
+				opts.Head = append(opts.Head, mhtml.TOCSidebarCSS...)
+			}
+			if mhtml.HasComments(doc) && !*flagFragment {
+				opts.Head = append(opts.Head, mhtml.CommentCSS...)
+			}
+			if *flagHighlight != "" && !*flagFragment {
+				css, err := mhtml.HighlightCSS(*flagHighlight)
+				if err != nil {
+					log.Fatal(err)
+				}
+				if css != nil {
+					opts.Head = append(opts.Head, mhtml.StyleSheet(css)...)
+				}
+			}
+			if *flagStandalone && opts.CSS != "" && !strings.Contains(opts.CSS, "://") {
+				css, err := init.ReadFile(opts.CSS)
+				if err != nil {
+					log.Printf("Couldn't open %q, error: %q", opts.CSS, err)
+					continue
+				}
+				opts.Head = append(opts.Head, mhtml.StyleSheet(css)...)
+				opts.CSS = ""
+			}
 			if *flagHead != "" {
 				head, err := ioutil.ReadFile(*flagHead)
 				if err != nil {
 					log.Printf("Couldn't open %q, error: %q", *flagHead, err)
 					continue
 				}
-				opts.Head = head
+				opts.Head = append(opts.Head, head...)
 			}
 			if documentTitle != "" {
 				opts.Title = documentTitle
 			}
+			searchIndex, searchScript := mhtml.SearchFiles(fileName)
+			if *flagBook != "" {
+				searchScript = mhtml.SearchScriptFile
+			}
+			if *flagSearch && fileName == "os.Stdin" && *flagBook == "" {
+				log.Printf("Couldn't write search index for %q: -search needs an input file or -book", fileName)
+			} else if *flagSearch {
+				opts.Head = append(opts.Head, mhtml.SearchScriptTag(filepath.Base(searchScript))...)
+			}
+
+			if *flagBook != "" {
+				if err := writeBook(*flagBook, doc, opts, toc, style); err != nil {
+					log.Printf("Couldn't write book for %q: %s", fileName, err)
+				}
+				if err := renderErr(); err != nil {
+					log.Printf("Failed to render %q: %s", fileName, err)
+				}
+				continue
+			}
+			if *flagSearch && fileName != "os.Stdin" {
+				if err := writeSearch(searchIndex, searchScript, doc, nil); err != nil {
+					log.Printf("Couldn't write search index for %q: %s", fileName, err)
+				}
+			}
 
 			renderer = html.NewRenderer(opts)
 		} else if *flagTwo {
+			mparser.ArtSets(doc, init.ReadFile)
+			xml.FootnoteParagraphs(doc)
 			opts := xml2.RendererOptions{
-				Flags:    xml2.CommonFlags,
-				Comments: [][]byte{[]byte("//"), []byte("#")},
+				Flags:       xml2.CommonFlags,
+				Comments:    [][]byte{[]byte("//"), []byte("#")},
+				Language:    language,
+				Footnotes:   footnotes,
+				UnknownNode: unknown,
 			}
 			if *flagFragment {
 				opts.Flags |= xml2.XMLFragment
 			}
 
-			renderer = xml2.NewRenderer(opts)
+			r := xml2.NewRenderer(opts)
+			renderErr = r.Err
+			renderer = r
 		} else {
+			mparser.ArtSets(doc, init.ReadFile)
+			xml.FootnoteParagraphs(doc)
 			opts := xml.RendererOptions{
-				Flags:    xml.CommonFlags,
-				Comments: [][]byte{[]byte("//"), []byte("#")},
+				Flags:       xml.CommonFlags,
+				Comments:    [][]byte{[]byte("//"), []byte("#")},
+				Language:    language,
+				Footnotes:   footnotes,
+				UnknownNode: unknown,
 			}
 			if *flagFragment {
 				opts.Flags |= xml.XMLFragment
 			}
 
-			renderer = xml.NewRenderer(opts)
+			r := xml.NewRenderer(opts)
+			renderErr = r.Err
+			renderer = r
 		}
 
 		x := markdown.Render(doc, renderer)
+		if err := renderErr(); err != nil {
+			log.Printf("Failed to render %q: %s", fileName, err)
+			continue
+		}
 		fmt.Println(string(x))
 	}
 }
 
-func addBibliography(doc ast.Node) bool {
-	where := mparser.NodeBackMatter(doc)
-	if where == nil {
-		return false
+// writeBook renders doc as a multi-page book in dir, the table of contents options are taken from toc
+// and citations are labeled with style.
+func writeBook(dir string, doc ast.Node, opts html.RendererOptions, toc mhtml.TOC, style mhtml.CitationStyle) error {
+	split := mhtml.SplitChapter
+	switch *flagBookSplit {
+	case "chapter":
+	case "matter":
+		split = mhtml.SplitMatter
+	default:
+		return fmt.Errorf("unknown book split %q", *flagBookSplit)
 	}
-
-	norm, inform := mparser.CitationToBibliography(doc)
-	if norm != nil {
-		ast.AppendChild(where, norm)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
 	}
-	if inform != nil {
-		ast.AppendChild(where, inform)
+
+	book := mhtml.NewBook(doc, split)
+	book.TOC.Depth = toc.Depth
+	book.TOC.Sidebar = toc.Sidebar
+	book.Bibliography.Style = style
+	if *flagSearch {
+		if err := writeSearch(filepath.Join(dir, mhtml.SearchIndexFile), filepath.Join(dir, mhtml.SearchScriptFile), doc, book.Href); err != nil {
+			return err
+		}
 	}
-	return (norm != nil) || (inform != nil)
+	return book.Render(opts, func(name string, data []byte) error {
+		path := filepath.Join(dir, name)
+		if filepath.Dir(path) != filepath.Clean(dir) {
+			return fmt.Errorf("page %q is not in %q", name, dir)
+		}
+		return ioutil.WriteFile(path, data, 0644)
+	})
 }
 
-func addIndex(doc ast.Node) bool {
-	idx := mparser.IndexToDocumentIndex(doc)
-	if idx == nil {
-		return false
+// writeSearch writes the search index of doc to the file index and the search script to the file
+// script, href is used to link to the sections.
+func writeSearch(index, script string, doc ast.Node, href func(id string) string) error {
+	data, err := mhtml.SearchIndexJSON(doc, href)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(index, data, 0644); err != nil {
+		return err
 	}
+	return ioutil.WriteFile(script, mhtml.SearchScript(data), 0644)
+}
 
-	ast.AppendChild(doc, idx)
-	return true
+// transformFlags enables and disables the passes in r, enable and disable are comma separated
+// lists of pass names.
+func transformFlags(r *transform.Registry, enable, disable string) error {
+	for _, name := range strings.Split(enable, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if err := r.Enable(name); err != nil {
+			return fmt.Errorf("%s, known passes are: %s", err, strings.Join(r.Names(), ", "))
+		}
+	}
+	for _, name := range strings.Split(disable, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if err := r.Disable(name); err != nil {
+			return fmt.Errorf("%s, known passes are: %s", err, strings.Join(r.Names(), ", "))
+		}
+	}
+	return nil
 }
 
 // Extensions is exported to we can use it in tests.
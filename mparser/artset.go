@@ -0,0 +1,182 @@
+package mparser
+
+import (
+	"bytes"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/diagram"
+	"github.com/mmarkdown/mmark/mast"
+)
+
+// ArtSets pairs SVG images with the ascii-art code block that follows them and replaces each pair
+// with a mast.ArtSet. A pair is either the only content of a figure block (in any order), where the
+// code block may be untagged, or a paragraph holding just the image, directly followed by a code
+// block tagged "ascii-art". The SVG files are read with read.
+func ArtSets(doc ast.Node, read func(file string) ([]byte, error)) {
+	type pair struct {
+		para *ast.Paragraph
+		img  *ast.Image
+		art  *ast.CodeBlock
+	}
+	pairs := []pair{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch node := node.(type) {
+		case *ast.CaptionFigure:
+			p := pair{}
+			for _, child := range node.GetChildren() {
+				switch child := child.(type) {
+				case *ast.Caption:
+				case *ast.Paragraph:
+					if p.img != nil {
+						return ast.SkipChildren
+					}
+					if p.img = svgImage(child); p.img == nil {
+						return ast.SkipChildren
+					}
+					p.para = child
+				case *ast.CodeBlock:
+					if p.art != nil || (len(child.Info) > 0 && !isASCIIArt(child)) {
+						return ast.SkipChildren
+					}
+					p.art = child
+				default:
+					return ast.SkipChildren
+				}
+			}
+			if p.img != nil && p.art != nil {
+				pairs = append(pairs, p)
+			}
+			return ast.SkipChildren
+		case *ast.Paragraph:
+			img := svgImage(node)
+			if img == nil {
+				return ast.SkipChildren
+			}
+			if art, ok := ast.GetNextNode(node).(*ast.CodeBlock); ok && isASCIIArt(art) {
+				pairs = append(pairs, pair{node, img, art})
+			}
+			return ast.SkipChildren
+		}
+		return ast.GoToNext
+	})
+
+	for _, p := range pairs {
+		file := string(p.img.Destination)
+		data, err := read(file)
+		if err != nil {
+			log.Printf("Failure to read SVG %q: %s", file, err)
+			continue
+		}
+		i := bytes.Index(data, []byte("<svg"))
+		if i < 0 {
+			log.Printf("No <svg> element found in %q", file)
+			continue
+		}
+
+		set := &mast.ArtSet{SVG: bytes.TrimSpace(data[i:]), Src: p.img.Destination}
+		for _, child := range p.img.GetChildren() {
+			if t, ok := child.(*ast.Text); ok {
+				set.Alt = append(set.Alt, t.Literal...)
+			}
+		}
+		// The art set takes the place of the image, the code block moves into it.
+		parent := p.para.GetParent()
+		children := parent.GetChildren()
+		for i, child := range children {
+			if child == p.para {
+				children[i] = set
+			}
+		}
+		set.SetParent(parent)
+		parent.SetChildren(children)
+		ast.AppendChild(set, p.art)
+	}
+}
+
+// ASCIIArtToSVG converts the ascii-art code blocks in doc to SVG and puts each in a mast.ArtSet
+// together with its SVG. Only the code blocks tagged "ascii-art", i.e. "~~~ ascii-art", or with the
+// block attribute {type="ascii-art"} are converted. Code blocks that already have an SVG image next
+// to them are left alone, ArtSets pairs those.
+func ASCIIArtToSVG(doc ast.Node) {
+	arts := []*ast.CodeBlock{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		code, ok := node.(*ast.CodeBlock)
+		if !ok || !entering {
+			return ast.GoToNext
+		}
+		if !isASCIIArt(code) {
+			return ast.GoToNext
+		}
+		if _, ok := code.Parent.(*mast.ArtSet); ok || hasSVGImage(code) {
+			return ast.GoToNext
+		}
+		arts = append(arts, code)
+		return ast.GoToNext
+	})
+
+	for _, art := range arts {
+		set := &mast.ArtSet{SVG: diagram.SVG(art.Literal)}
+		parent := art.GetParent()
+		children := parent.GetChildren()
+		for i, child := range children {
+			if child == art {
+				children[i] = set
+			}
+		}
+		set.SetParent(parent)
+		parent.SetChildren(children)
+		ast.AppendChild(set, art)
+	}
+}
+
+// hasSVGImage returns true if the code block is directly preceded by an SVG image, or is in a figure
+// with one.
+func hasSVGImage(code *ast.CodeBlock) bool {
+	if para, ok := ast.GetPrevNode(code).(*ast.Paragraph); ok && svgImage(para) != nil {
+		return true
+	}
+	if figure, ok := code.Parent.(*ast.CaptionFigure); ok {
+		for _, child := range figure.GetChildren() {
+			if para, ok := child.(*ast.Paragraph); ok && svgImage(para) != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// svgImage returns the image in para if it is the only thing in it, and it is an SVG file.
+func svgImage(para *ast.Paragraph) *ast.Image {
+	var img *ast.Image
+	for _, child := range para.GetChildren() {
+		switch child := child.(type) {
+		case *ast.Image:
+			if img != nil {
+				return nil
+			}
+			img = child
+		case *ast.Text:
+			if len(bytes.TrimSpace(child.Literal)) > 0 {
+				return nil
+			}
+		default:
+			return nil
+		}
+	}
+	if img == nil || !strings.EqualFold(filepath.Ext(string(img.Destination)), ".svg") {
+		return nil
+	}
+	return img
+}
+
+// isASCIIArt returns true if the code block is tagged "ascii-art", i.e. "~~~ ascii-art", or with
+// the block attribute {type="ascii-art"}.
+func isASCIIArt(code *ast.CodeBlock) bool {
+	return string(code.Info) == "ascii-art" || string(mast.Attribute(code, "type")) == "ascii-art"
+}
@@ -0,0 +1,65 @@
+package mparser
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/mast"
+)
+
+func TestArtSets(t *testing.T) {
+	in := "!---\n![Box](box.svg)\n~~~\n+-+\n~~~\n!---\nFigure: Box.\n\n![Box](box.svg)\n~~~ go\nx := 1\n~~~\n\n" +
+		"![Box](box.svg)\n~~~\nx := 1\n~~~\n\n![Line](line.svg)\n\n{type=\"ascii-art\"}\n~~~\n---\n~~~\n"
+	doc := markdown.Parse([]byte(in), parser.NewWithExtensions(parser.CommonExtensions|parser.Attributes|parser.Mmark))
+	read := func(file string) ([]byte, error) {
+		return []byte(`<?xml version="1.0"?>` + "\n" + `<svg xmlns="http://www.w3.org/2000/svg"/>`), nil
+	}
+	ArtSets(doc, read)
+
+	sets := []*mast.ArtSet{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if set, ok := node.(*mast.ArtSet); ok && entering {
+			sets = append(sets, set)
+		}
+		return ast.GoToNext
+	})
+	if len(sets) != 2 {
+		t.Fatalf("want 2 art sets, got %d", len(sets))
+	}
+	if string(sets[1].Src) != "line.svg" {
+		t.Errorf("want tagged code block paired with line.svg, got %q", sets[1].Src)
+	}
+	set := sets[0]
+	if string(set.SVG) != `<svg xmlns="http://www.w3.org/2000/svg"/>` || string(set.Alt) != "Box" {
+		t.Errorf("want SVG element and alt text, got %q %q", set.SVG, set.Alt)
+	}
+	if _, ok := set.Parent.(*ast.CaptionFigure); !ok {
+		t.Errorf("want art set in the figure, got %T", set.Parent)
+	}
+	if art, ok := ast.GetFirstChild(set).(*ast.CodeBlock); !ok || string(art.Literal) != "+-+\n" {
+		t.Errorf("want ascii-art as child, got %v", ast.GetFirstChild(set))
+	}
+}
+
+func TestASCIIArtToSVG(t *testing.T) {
+	in := "~~~ ascii-art\n+-+\n~~~\n\n{type=\"ascii-art\"}\n~~~\n+-+\n~~~\n\n~~~\n+-+\n~~~\n\n![Box](box.svg)\n~~~ ascii-art\n+-+\n~~~\n"
+	doc := markdown.Parse([]byte(in), parser.NewWithExtensions(parser.CommonExtensions|parser.Attributes|parser.Mmark))
+	ASCIIArtToSVG(doc)
+
+	sets := 0
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if set, ok := node.(*mast.ArtSet); ok && entering {
+			sets++
+			if !bytes.HasPrefix(set.SVG, []byte("<svg")) {
+				t.Errorf("want SVG in art set, got %q", set.SVG)
+			}
+		}
+		return ast.GoToNext
+	})
+	if sets != 2 {
+		t.Errorf("want 2 art sets, got %d", sets)
+	}
+}
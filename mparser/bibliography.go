@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/xml"
 	"log"
+	"sort"
+	"strings"
 
 	"github.com/gomarkdown/markdown/ast"
 	"github.com/mmarkdown/mmark/mast"
@@ -40,7 +42,15 @@ func CitationToBibliography(doc ast.Node) (normative ast.Node, informative ast.N
 		return ast.GoToNext
 	})
 
-	for _, r := range seen {
+	// Sort on anchor, so the output is stable.
+	anchors := make([]string, 0, len(seen))
+	for a := range seen {
+		anchors = append(anchors, a)
+	}
+	sort.Slice(anchors, func(i, j int) bool { return strings.ToLower(anchors[i]) < strings.ToLower(anchors[j]) })
+
+	for _, a := range anchors {
+		r := seen[a]
 		// If we have a reference anchor and the raw XML add that here.
 		if raw, ok := raw[string(bytes.ToLower(r.Anchor))]; ok {
 			var x reference.Reference
@@ -0,0 +1,175 @@
+package mparser
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/mast"
+)
+
+var (
+	commentOpen  = []byte("[[")
+	commentClose = []byte("]]")
+)
+
+// TextToComments crawls the entire doc searching for editorial comments in the text, i.e.
+// "[[ alice: fix this ]]", and replaces them with mast.Comment nodes. A comment may contain other
+// inline elements, but it can't span paragraphs. The ID of a heading that was generated from its
+// text is generated again, without the text of the comments.
+func TextToComments(doc ast.Node) {
+	parents := []ast.Node{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if t, ok := node.(*ast.Text); ok && entering && bytes.Contains(t.Literal, commentOpen) {
+			if p := t.Parent; len(parents) == 0 || parents[len(parents)-1] != p {
+				parents = append(parents, p)
+			}
+		}
+		return ast.GoToNext
+	})
+	for _, p := range parents {
+		textToComments(p)
+	}
+}
+
+func textToComments(parent ast.Node) {
+	children := parent.GetChildren()
+	auto := anchorName(parent)
+	out := []ast.Node{}
+	var comment *mast.Comment
+	add := func(node ast.Node) {
+		if comment != nil {
+			node.SetParent(comment)
+			comment.Children = append(comment.Children, node)
+			return
+		}
+		node.SetParent(parent)
+		out = append(out, node)
+	}
+
+	for i, child := range children {
+		t, ok := child.(*ast.Text)
+		if !ok {
+			add(child)
+			continue
+		}
+		text := t.Literal
+		for len(text) > 0 {
+			if comment == nil {
+				j := bytes.Index(text, commentOpen)
+				if j < 0 || !commentCloses(text[j+len(commentOpen):], children[i+1:]) {
+					add(newText(text))
+					break
+				}
+				if j > 0 {
+					add(newText(text[:j]))
+				}
+				comment = &mast.Comment{}
+				text = text[j+len(commentOpen):]
+				continue
+			}
+			j := bytes.Index(text, commentClose)
+			if j < 0 {
+				add(newText(text))
+				break
+			}
+			if j > 0 {
+				add(newText(text[:j]))
+			}
+			commentSource(comment)
+			comment.SetParent(parent)
+			out = append(out, comment)
+			comment = nil
+			text = text[j+len(commentClose):]
+		}
+	}
+	parent.SetChildren(out)
+	if h, ok := parent.(*ast.Heading); ok {
+		headingID(h, auto)
+	}
+}
+
+// headingID generates the ID of h again, when it is the ID auto that was generated from the text
+// of h with the comments still in it. A "-N" suffix, added to make the ID unique, is kept.
+func headingID(h *ast.Heading, auto string) {
+	if !strings.HasPrefix(h.HeadingID, auto) {
+		return
+	}
+	suffix := h.HeadingID[len(auto):]
+	if suffix != "" {
+		if _, err := strconv.Atoi(strings.TrimPrefix(suffix, "-")); err != nil || suffix[0] != '-' {
+			return
+		}
+	}
+	h.HeadingID = anchorName(h) + suffix
+}
+
+// anchorName returns the ID the parser generates from the text of node, comments are skipped.
+func anchorName(node ast.Node) string {
+	var name []rune
+	dash := false
+	ast.WalkFunc(node, func(n ast.Node, entering bool) ast.WalkStatus {
+		if _, ok := n.(*mast.Comment); ok {
+			return ast.SkipChildren
+		}
+		leaf := n.AsLeaf()
+		if leaf == nil || !entering {
+			return ast.GoToNext
+		}
+		for _, r := range string(leaf.Literal) {
+			if !unicode.IsLetter(r) && !unicode.IsNumber(r) {
+				dash = true
+				continue
+			}
+			if dash && len(name) > 0 {
+				name = append(name, '-')
+			}
+			dash = false
+			name = append(name, unicode.ToLower(r))
+		}
+		return ast.GoToNext
+	})
+	if len(name) == 0 {
+		return "empty"
+	}
+	return string(name)
+}
+
+// commentCloses returns true if a comment opened just before text is closed in text or in one of
+// the text nodes in siblings.
+func commentCloses(text []byte, siblings []ast.Node) bool {
+	if bytes.Contains(text, commentClose) {
+		return true
+	}
+	for _, s := range siblings {
+		if t, ok := s.(*ast.Text); ok && bytes.Contains(t.Literal, commentClose) {
+			return true
+		}
+	}
+	return false
+}
+
+// commentSource sets the source of the comment when its text starts with "name: " and trims the
+// whitespace around the text.
+func commentSource(comment *mast.Comment) {
+	children := comment.GetChildren()
+	if len(children) == 0 {
+		return
+	}
+	if first, ok := children[0].(*ast.Text); ok {
+		first.Literal = bytes.TrimLeft(first.Literal, " \t\n")
+		if i := bytes.Index(first.Literal, []byte(": ")); i > 0 && !bytes.ContainsAny(first.Literal[:i], " \t\n") {
+			comment.Source = first.Literal[:i]
+			first.Literal = bytes.TrimLeft(first.Literal[i+2:], " \t\n")
+		}
+	}
+	if last, ok := children[len(children)-1].(*ast.Text); ok {
+		last.Literal = bytes.TrimRight(last.Literal, " \t\n")
+	}
+}
+
+func newText(text []byte) *ast.Text {
+	return &ast.Text{Leaf: ast.Leaf{Literal: text}}
+}
@@ -0,0 +1,47 @@
+package mparser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/mast"
+)
+
+func TestTextToComments(t *testing.T) {
+	in := "Text [[ alice: fix *this* ]] here.\n\n[[ bob: a block comment ]]\n\nA [[plain]] one.\n"
+	doc := markdown.Parse([]byte(in), parser.New())
+	TextToComments(doc)
+
+	got := []string{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if c, ok := node.(*mast.Comment); ok && entering {
+			got = append(got, string(c.Source)+"|"+string(ast.GetFirstChild(c).AsLeaf().Literal))
+		}
+		return ast.GoToNext
+	})
+	want := []string{"alice|fix ", "bob|a block comment", "|plain"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestTextToCommentsHeadingID(t *testing.T) {
+	in := "# Intro [[bob: note]]\n\n# Intro [[bob: note]]\n\n# Other [[bob: note]] {#other}\n"
+	doc := markdown.Parse([]byte(in), parser.NewWithExtensions(parser.CommonExtensions|parser.AutoHeadingIDs))
+	TextToComments(doc)
+
+	got := []string{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if h, ok := node.(*ast.Heading); ok && entering {
+			got = append(got, h.HeadingID)
+		}
+		return ast.GoToNext
+	})
+	want := []string{"intro", "intro-1", "other"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
@@ -1,6 +1,7 @@
 package mparser
 
 import (
+	"fmt"
 	"io/ioutil"
 	"log"
 	"path/filepath"
@@ -11,16 +12,32 @@ import (
 
 var UnsafeInclude parser.Flags = 1 << 3
 
-// Hook will call both TitleHook and ReferenceHook.
+// Hook will call TitleHook, TocHook and ReferenceHook.
 func Hook(data []byte) (ast.Node, []byte, int) {
 	n, b, i := TitleHook(data)
 	if n != nil {
 		return n, b, i
 	}
 
+	n, b, i = TocHook(data)
+	if n != nil {
+		return n, b, i
+	}
+
 	return ReferenceHook(data)
 }
 
+// Syntax finishes the parsing of doc, it recognizes the mmark syntax the markdown parser leaves as
+// text, code or tables: code includes, editorial comments, ascii-art, table spans and raw content.
+// It must be called on the document returned by the parser, before it's transformed or rendered.
+func Syntax(doc ast.Node) {
+	CodeIncludes(doc)
+	TextToComments(doc)
+	ASCIIArtToSVG(doc)
+	TableSpans(doc)
+	TextToRaw(doc)
+}
+
 // ReadInclude is the hook to read includes.
 // Its supports the following options for address.
 //
@@ -52,5 +69,22 @@ func (i Initial) ReadInclude(from, file string, address []byte) []byte {
 	if data[len(data)-1] != '\n' {
 		data = append(data, '\n')
 	}
+	if i.reads != nil {
+		*i.reads = append(*i.reads, included{file: file, data: data})
+	}
 	return data
 }
+
+// ReadFile reads file, relative to the initial file. Unless UnsafeInclude is set, only files on the
+// same level or below the initial file can be read.
+func (i Initial) ReadFile(file string) ([]byte, error) {
+	path := i.path("", file)
+
+	if i.Flags&UnsafeInclude == 0 {
+		if ok := i.pathAllowed(path); !ok {
+			return nil, fmt.Errorf("path %q is not on or below %q", path, i.i)
+		}
+	}
+
+	return ioutil.ReadFile(path)
+}
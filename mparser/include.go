@@ -18,26 +18,148 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/gomarkdown/markdown/ast"
 	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/mast"
 )
 
 // Initial is the initial file we are working on, empty for stdin and adjusted is we we have an absolute or relative file.
 type Initial struct {
 	Flags parser.Flags
 	i     string
+	reads *[]included // the files read by ReadInclude since the last call of CodeIncludeHook
+}
+
+// included is a file that has been included and the data that was included from it.
+type included struct {
+	file string
+	data []byte
 }
 
 // NewInitial returns an initialized Initial.
 func NewInitial(s string) Initial {
 	if path.IsAbs(s) {
-		return Initial{i: path.Dir(s)}
+		return Initial{i: path.Dir(s), reads: &[]included{}}
 	}
 
 	cwd, _ := os.Getwd()
 	if s == "" {
-		return Initial{i: cwd}
+		return Initial{i: cwd, reads: &[]included{}}
+	}
+	return Initial{i: path.Dir(filepath.Join(cwd, s)), reads: &[]included{}}
+}
+
+// codeInclude marks the code block that is included with "<{{file}}", the code block (or the figure
+// holding it) is the next sibling. The parser puts the block attributes of the include on it.
+type codeInclude struct {
+	ast.Leaf
+
+	file string
+}
+
+// CodeIncludeHook recognizes the code block the parser makes of a "<{{file}}" include and marks it
+// with the name of the included file, see CodeIncludes. The parser calls the hook right after the
+// include is read, on the included data wrapped in a fenced code block: data must start with a fence
+// that holds exactly the data of one of the files read since the last call. The fence is made longer
+// when the data has a fence of its own.
+func (i Initial) CodeIncludeHook(data []byte) (ast.Node, []byte, int) {
+	if i.reads == nil || len(*i.reads) == 0 {
+		return nil, nil, 0
+	}
+	reads := *i.reads
+	*i.reads = nil
+
+	fence, info := openingFence(data)
+	if fence == nil {
+		return nil, nil, 0
+	}
+	body := data[len(fence)+len(info):]
+	for j := len(reads) - 1; j >= 0; j-- {
+		inc := reads[j]
+		if !bytes.HasPrefix(body, inc.data) {
+			continue
+		}
+		caption := body[len(inc.data):]
+		if !bytes.HasPrefix(caption, fence) {
+			continue
+		}
+		caption = caption[len(fence):]
+		k := bytes.IndexByte(caption, '\n')
+		if k < 0 || len(bytes.TrimSpace(caption[:k])) > 0 {
+			continue
+		}
+		caption = caption[k+1:]
+
+		for bytes.Contains(inc.data, fence) {
+			fence = append(fence, fence[0])
+		}
+		block := &bytes.Buffer{}
+		block.Write(fence)
+		block.Write(info)
+		block.Write(inc.data)
+		block.Write(fence)
+		block.WriteByte('\n')
+		block.Write(caption)
+		return &codeInclude{file: path.Base(inc.file)}, block.Bytes(), len(data)
+	}
+	return nil, nil, 0
+}
+
+// openingFence returns the fence ("```" or "~~~", or longer) and the rest of the line, up to and
+// including the newline, that data starts with. If data doesn't start with a fence, fence is nil.
+func openingFence(data []byte) (fence, info []byte) {
+	n := 0
+	for n < len(data) && (data[n] == '`' || data[n] == '~') && data[n] == data[0] {
+		n++
+	}
+	if n < 3 {
+		return nil, nil
+	}
+	end := bytes.IndexByte(data[n:], '\n')
+	if end < 0 {
+		return nil, nil
+	}
+	return append([]byte{}, data[:n]...), data[n : n+end+1]
+}
+
+// CodeIncludes sets the "name" attribute of the code blocks in doc that are included with "<{{file}}"
+// to the name of the file, unless they already have a name. The block attributes of the include are
+// moved to the code block.
+func CodeIncludes(doc ast.Node) {
+	includes := []*codeInclude{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if inc, ok := node.(*codeInclude); ok && entering {
+			includes = append(includes, inc)
+		}
+		return ast.GoToNext
+	})
+
+	for _, inc := range includes {
+		next := ast.GetNextNode(inc)
+		ast.RemoveFromTree(inc)
+
+		code, ok := next.(*ast.CodeBlock)
+		if figure, isFigure := next.(*ast.CaptionFigure); isFigure {
+			code, ok = ast.GetFirstChild(figure).(*ast.CodeBlock)
+			figure.Attribute = inc.Attribute
+		}
+		if !ok {
+			continue
+		}
+		if inc.Attribute != nil {
+			// A copy, the renderers filter the attributes of the figure and the code block separately.
+			attr := *inc.Attribute
+			attr.Attrs = map[string][]byte{}
+			for k, v := range inc.Attrs {
+				attr.Attrs[k] = v
+			}
+			code.Attribute = &attr
+		}
+		if mast.Attribute(code, "name") == nil {
+			mast.AttributeInit(code)
+			mast.SetAttribute(code, "name", []byte(inc.file))
+		}
 	}
-	return Initial{i: path.Dir(filepath.Join(cwd, s))}
 }
 
 // path returns the full path we should use according to from, file and initial.
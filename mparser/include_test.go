@@ -0,0 +1,113 @@
+package mparser
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/mast"
+)
+
+func TestCodeIncludes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mmark")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"hello.c":  "int x;\n",
+		"fence.md": "```\ncode\n```\n",
+	}
+	for file, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, file), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	in := "<{{hello.c}}\n\n" +
+		"``` c\nint x;\n```\n\n" + // same as the include, but not included
+		"{markers=\"true\"}\n<{{fence.md}}\n\n" +
+		"<{{hello.c}}\nFigure: Hello.\n\n" +
+		"{name=\"main.c\"}\n<{{hello.c}}\n"
+
+	init := NewInitial(filepath.Join(dir, "doc.md"))
+	p := parser.NewWithExtensions(parser.CommonExtensions | parser.Attributes | parser.Mmark)
+	p.Opts = parser.ParserOptions{ParserHook: init.CodeIncludeHook, ReadIncludeFn: init.ReadInclude}
+	doc := markdown.Parse([]byte(in), p)
+	CodeIncludes(doc)
+
+	codes := []*ast.CodeBlock{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if _, ok := node.(*codeInclude); ok {
+			t.Errorf("want no code include markers left")
+		}
+		if code, ok := node.(*ast.CodeBlock); ok && entering {
+			codes = append(codes, code)
+		}
+		return ast.GoToNext
+	})
+	if len(codes) != 5 {
+		t.Fatalf("want 5 code blocks, got %d", len(codes))
+	}
+
+	tests := []struct {
+		name    string
+		literal string
+	}{
+		{"hello.c", files["hello.c"]},
+		{"", files["hello.c"]},
+		{"fence.md", files["fence.md"]},
+		{"hello.c", files["hello.c"]},
+		{"main.c", files["hello.c"]},
+	}
+	for i, tc := range tests {
+		if name := mast.Attribute(codes[i], "name"); string(name) != tc.name {
+			t.Errorf("want name %q, got %q, for code block %d", tc.name, name, i)
+		}
+		if string(codes[i].Literal) != tc.literal {
+			t.Errorf("want literal %q, got %q, for code block %d", tc.literal, codes[i].Literal, i)
+		}
+	}
+	if markers := mast.Attribute(codes[2], "markers"); string(markers) != "true" {
+		t.Errorf("want markers %q, got %q", "true", markers)
+	}
+	if _, ok := codes[3].Parent.(*ast.CaptionFigure); !ok {
+		t.Errorf("want code block in a figure, got %T", codes[3].Parent)
+	}
+}
+
+func TestCodeIncludeHook(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mmark")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for file, data := range map[string]string{"a.md": "<{{b.go}}\n", "b.go": "x := 1\n"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, file), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Two files are read before the hook runs, the code block holds the second.
+	init := NewInitial(filepath.Join(dir, "doc.md"))
+	init.ReadInclude("", "a.md", nil)
+	init.ReadInclude("", "b.go", nil)
+	in := []byte("~~~~ golang\nx := 1\n~~~~\nFigure: B.\n")
+	node, data, consumed := init.CodeIncludeHook(in)
+	inc, ok := node.(*codeInclude)
+	if !ok || inc.file != "b.go" {
+		t.Fatalf("want code include of b.go, got %v", node)
+	}
+	if string(data) != string(in) || consumed != len(in) {
+		t.Errorf("want the code block and its caption, got %q (%d)", data, consumed)
+	}
+
+	// The reads are forgotten once the hook ran.
+	if node, _, _ := init.CodeIncludeHook([]byte("```\nx := 1\n```\n")); node != nil {
+		t.Errorf("want no code include, got %v", node)
+	}
+}
@@ -0,0 +1,74 @@
+package mparser
+
+import (
+	"bytes"
+	"log"
+	"regexp"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/mast"
+)
+
+// RawFormats are the formats raw content can be tagged with.
+var RawFormats = map[string]bool{"xml3": true, "xml2": true, "html": true}
+
+var (
+	rawFormat      = regexp.MustCompile(`^\{=([a-z0-9]+)\}`)
+	rawBlockFormat = regexp.MustCompile(`^\{?=([a-z0-9]+)\}?$`) // the parser may strip the braces from the info string
+)
+
+// TextToRaw crawls the entire doc for fenced code blocks and code spans tagged with a format, i.e.
+// "```{=xml3}" or "`<u>text</u>`{=xml3}", and replaces them with mast.Raw nodes.
+func TextToRaw(doc ast.Node) {
+	type raw struct {
+		node ast.Node
+		raw  *mast.Raw
+	}
+	raws := []raw{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch node := node.(type) {
+		case *ast.CodeBlock:
+			m := rawBlockFormat.FindSubmatch(bytes.TrimSpace(node.Info))
+			if m == nil || !isRawFormat(m[1]) {
+				return ast.GoToNext
+			}
+			raws = append(raws, raw{node, &mast.Raw{Leaf: ast.Leaf{Literal: node.Literal}, Format: string(m[1]), Block: true}})
+		case *ast.Code:
+			next, ok := ast.GetNextNode(node).(*ast.Text)
+			if !ok {
+				return ast.GoToNext
+			}
+			m := rawFormat.FindSubmatch(next.Literal)
+			if m == nil || !isRawFormat(m[1]) {
+				return ast.GoToNext
+			}
+			next.Literal = next.Literal[len(m[0]):]
+			raws = append(raws, raw{node, &mast.Raw{Leaf: ast.Leaf{Literal: node.Literal}, Format: string(m[1])}})
+		}
+		return ast.GoToNext
+	})
+
+	for _, r := range raws {
+		parent := r.node.GetParent()
+		children := parent.GetChildren()
+		for i, child := range children {
+			if child == r.node {
+				children[i] = r.raw
+			}
+		}
+		r.raw.SetParent(parent)
+		parent.SetChildren(children)
+	}
+}
+
+// isRawFormat returns true if format is one of the RawFormats, unknown formats are logged.
+func isRawFormat(format []byte) bool {
+	if !RawFormats[string(format)] {
+		log.Printf("Unknown raw format %q, treating it as code", format)
+		return false
+	}
+	return true
+}
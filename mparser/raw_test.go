@@ -0,0 +1,40 @@
+package mparser
+
+import (
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/mast"
+)
+
+func TestTextToRaw(t *testing.T) {
+	in := "```{=xml3}\n<u>x</u>\n```\n\nText `<b>y</b>`{=html} and `code`{=go}.\n"
+	doc := markdown.Parse([]byte(in), parser.NewWithExtensions(parser.CommonExtensions))
+	TextToRaw(doc)
+
+	raws := []*mast.Raw{}
+	codes := 0
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		switch node := node.(type) {
+		case *mast.Raw:
+			raws = append(raws, node)
+		case *ast.Code:
+			codes++
+		}
+		return ast.GoToNext
+	})
+	if len(raws) != 2 || codes != 1 {
+		t.Fatalf("want 2 raw nodes and 1 code span, got %d and %d", len(raws), codes)
+	}
+	if !raws[0].Block || raws[0].Format != "xml3" || string(raws[0].Literal) != "<u>x</u>\n" {
+		t.Errorf("want raw xml3 block, got %+v", raws[0])
+	}
+	if raws[1].Block || raws[1].Format != "html" || string(raws[1].Literal) != "<b>y</b>" {
+		t.Errorf("want raw html span, got %+v", raws[1])
+	}
+	if text, ok := ast.GetNextNode(raws[1]).(*ast.Text); !ok || string(text.Literal) != " and " {
+		t.Errorf("want format removed from text, got %v", ast.GetNextNode(raws[1]))
+	}
+}
@@ -0,0 +1,167 @@
+package mparser
+
+import (
+	"bytes"
+	"log"
+	"strconv"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/mast"
+)
+
+var (
+	rowSpan = []byte("^") // A cell holding only this is merged with the cell above it.
+	colSpan = []byte("<") // A cell holding only this is merged with the cell left of it.
+)
+
+// TableSpans crawls the entire doc for tables and merges the cells that hold only a "^" with the
+// cell above them, and the cells holding only a "<" with the cell left of them. The merged cells
+// are removed, the cell they are merged with gets a rowspan attribute or a larger ColSpan. Spans
+// don't cross the table header, body and footer.
+//
+// The block attributes "widths" and "aligns" of a table set the width and alignment of each
+// column, i.e. {widths="20% 80%" aligns="right left"}; use "-" to skip a column. The widths are put
+// on the header cells as a width attribute.
+func TableSpans(doc ast.Node) {
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if table, ok := node.(*ast.Table); ok && entering {
+			// Before the spans are merged, as that removes cells and shifts the columns.
+			tableColumns(table)
+			for _, section := range table.GetChildren() {
+				tableSectionSpans(section)
+			}
+			return ast.SkipChildren
+		}
+		return ast.GoToNext
+	})
+}
+
+// tableSectionSpans merges the cells of the rows in section.
+func tableSectionSpans(section ast.Node) {
+	above := map[int]*ast.TableCell{} // column -> cell covering it in the row above
+	for _, row := range section.GetChildren() {
+		cur := map[int]*ast.TableCell{}
+		cells := []ast.Node{}
+		var left *ast.TableCell
+		covered := 0 // "<" cells that are under the columns of a rowspan
+		col := 0
+		for _, child := range row.GetChildren() {
+			cell, ok := child.(*ast.TableCell)
+			if !ok {
+				cells = append(cells, child)
+				continue
+			}
+			switch {
+			case isSpan(cell, rowSpan) && above[col] != nil:
+				owner := above[col]
+				span := cellSpan(owner)
+				rows, _ := strconv.Atoi(string(mast.Attribute(owner, "rowspan")))
+				if rows == 0 {
+					rows = 1
+				}
+				mast.AttributeInit(owner)
+				mast.SetAttribute(owner, "rowspan", []byte(strconv.Itoa(rows+1)))
+				for i := 0; i < span; i++ {
+					cur[col+i] = owner
+				}
+				col += span
+				left = nil
+				covered = span - 1
+				continue
+			case isSpan(cell, colSpan) && covered > 0:
+				covered--
+				continue
+			case isSpan(cell, colSpan) && left != nil:
+				left.ColSpan = cellSpan(left) + 1
+				mast.DeleteAttribute(left, "width") // a column width doesn't apply to a span
+				cur[col] = left
+				col++
+				continue
+			}
+			span := cellSpan(cell)
+			for i := 0; i < span; i++ {
+				cur[col+i] = cell
+			}
+			col += span
+			left = cell
+			covered = 0
+			cells = append(cells, cell)
+		}
+		row.SetChildren(cells)
+		above = cur
+	}
+}
+
+// tableColumns applies the widths and aligns attributes of table to its columns.
+func tableColumns(table *ast.Table) {
+	widths := bytes.Fields(mast.Attribute(table, "widths"))
+	aligns := bytes.Fields(mast.Attribute(table, "aligns"))
+	mast.DeleteAttribute(table, "widths")
+	mast.DeleteAttribute(table, "aligns")
+	if len(widths) == 0 && len(aligns) == 0 {
+		return
+	}
+
+	ast.WalkFunc(table, func(node ast.Node, entering bool) ast.WalkStatus {
+		row, ok := node.(*ast.TableRow)
+		if !ok || !entering {
+			return ast.GoToNext
+		}
+		col := 0
+		for _, child := range row.GetChildren() {
+			cell, ok := child.(*ast.TableCell)
+			if !ok {
+				continue
+			}
+			if col < len(aligns) && string(aligns[col]) != "-" {
+				if align, ok := cellAligns[string(aligns[col])]; ok {
+					cell.Align = align
+				} else {
+					log.Printf("Unknown table column alignment %q", aligns[col])
+				}
+			}
+			if cell.IsHeader && col < len(widths) && string(widths[col]) != "-" && cellSpan(cell) == 1 {
+				mast.AttributeInit(cell)
+				mast.SetAttribute(cell, "width", widths[col])
+			}
+			col += cellSpan(cell)
+		}
+		return ast.SkipChildren
+	})
+}
+
+var cellAligns = map[string]ast.CellAlignFlags{
+	"left":   ast.TableAlignmentLeft,
+	"right":  ast.TableAlignmentRight,
+	"center": ast.TableAlignmentCenter,
+}
+
+// cellSpan returns the number of columns cell spans.
+func cellSpan(cell *ast.TableCell) int {
+	if cell.ColSpan > 1 {
+		return cell.ColSpan
+	}
+	return 1
+}
+
+// isSpan returns true if the only content of cell is the text marker.
+func isSpan(cell *ast.TableCell, marker []byte) bool {
+	var content ast.Node
+	for _, child := range cell.GetChildren() {
+		if t, ok := child.(*ast.Text); ok && len(bytes.TrimSpace(t.Literal)) == 0 {
+			continue
+		}
+		if content != nil {
+			return false
+		}
+		content = child
+	}
+	switch c := content.(type) {
+	case *ast.Text:
+		return bytes.Equal(bytes.TrimSpace(c.Literal), marker)
+	case *ast.Superscript:
+		// With super- and subscripts enabled a lone "^" is parsed as an empty superscript.
+		return bytes.Equal(marker, rowSpan) && len(c.GetChildren()) == 0 && len(c.Literal) == 0
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package mparser
+
+import (
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/mast"
+)
+
+func TestTableSpans(t *testing.T) {
+	in := `{widths="20% 80% -" aligns="right - center"}
+A | B | C
+---|---|---
+a | b | c
+^ | d | <
+`
+	p := parser.NewWithExtensions(parser.CommonExtensions | parser.Attributes | parser.SuperSubscript)
+	doc := markdown.Parse([]byte(in), p)
+	TableSpans(doc)
+
+	rows := [][]*ast.TableCell{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if row, ok := node.(*ast.TableRow); ok && entering {
+			cells := []*ast.TableCell{}
+			for _, c := range row.GetChildren() {
+				cells = append(cells, c.(*ast.TableCell))
+			}
+			rows = append(rows, cells)
+		}
+		return ast.GoToNext
+	})
+	if len(rows) != 3 || len(rows[0]) != 3 || len(rows[1]) != 3 || len(rows[2]) != 1 {
+		t.Fatalf("want rows with 3, 3 and 1 cells, got %v", rows)
+	}
+	if span := mast.Attribute(rows[1][0], "rowspan"); string(span) != "2" {
+		t.Errorf("want rowspan 2, got %q", span)
+	}
+	if rows[2][0].ColSpan != 2 {
+		t.Errorf("want colspan 2, got %d", rows[2][0].ColSpan)
+	}
+	if width := mast.Attribute(rows[0][1], "width"); string(width) != "80%" {
+		t.Errorf("want width 80%%, got %q", width)
+	}
+	if rows[1][0].Align != ast.TableAlignmentRight || rows[1][2].Align != ast.TableAlignmentCenter {
+		t.Errorf("want right and center aligned columns, got %s and %s", rows[1][0].Align, rows[1][2].Align)
+	}
+}
+
+func TestTableSpansCovered(t *testing.T) {
+	in := `A | B | C
+---|---|---
+x | < | y
+^ | < | z
+`
+	p := parser.NewWithExtensions(parser.CommonExtensions | parser.Attributes | parser.SuperSubscript)
+	doc := markdown.Parse([]byte(in), p)
+	TableSpans(doc)
+
+	rows := [][]*ast.TableCell{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if row, ok := node.(*ast.TableRow); ok && entering {
+			cells := []*ast.TableCell{}
+			for _, c := range row.GetChildren() {
+				cells = append(cells, c.(*ast.TableCell))
+			}
+			rows = append(rows, cells)
+		}
+		return ast.GoToNext
+	})
+	// The "<" under the span of x is covered by the rowspan.
+	if len(rows) != 3 || len(rows[1]) != 2 || len(rows[2]) != 1 {
+		t.Fatalf("want rows with 3, 2 and 1 cells, got %v", rows)
+	}
+	if rows[1][0].ColSpan != 2 {
+		t.Errorf("want colspan 2, got %d", rows[1][0].ColSpan)
+	}
+	if span := mast.Attribute(rows[1][0], "rowspan"); string(span) != "2" {
+		t.Errorf("want rowspan 2, got %q", span)
+	}
+}
@@ -0,0 +1,29 @@
+package mparser
+
+import (
+	"bytes"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/mast"
+)
+
+var tocMarker = []byte("{toc}")
+
+// TocHook will parse a {toc} marker on a line by itself and return a mast.TableOfContents node.
+func TocHook(data []byte) (ast.Node, []byte, int) {
+	if !bytes.HasPrefix(data, tocMarker) {
+		return nil, nil, 0
+	}
+	i := len(tocMarker)
+	for i < len(data) && (data[i] == ' ' || data[i] == '\t') {
+		i++
+	}
+	if i < len(data) && data[i] != '\n' {
+		return nil, nil, 0
+	}
+	if i < len(data) {
+		i++
+	}
+
+	return &mast.TableOfContents{}, nil, i
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/gomarkdown/markdown/parser"
 	"github.com/mmarkdown/mmark/mast"
 	"github.com/mmarkdown/mmark/mparser"
+	"github.com/mmarkdown/mmark/transform"
 	"github.com/mmarkdown/mmark/xml"
 	"github.com/mmarkdown/mmark/xml2"
 )
@@ -71,6 +72,9 @@ func doRenderTest(t *testing.T, dir, basename string, renderer markdown.Renderer
 	p := parser.NewWithExtensions(Extensions)
 	p.Opts = parser.ParserOptions{
 		ParserHook: func(data []byte) (ast.Node, []byte, int) {
+			if node, data, consumed := init.CodeIncludeHook(data); consumed > 0 {
+				return node, data, consumed
+			}
 			node, data, consumed := mparser.Hook(data)
 			if t, ok := node.(*mast.Title); ok {
 				_ = t.TitleData.Title
@@ -81,8 +85,8 @@ func doRenderTest(t *testing.T, dir, basename string, renderer markdown.Renderer
 	}
 
 	doc := markdown.Parse(input, p)
-	addBibliography(doc)
-	addIndex(doc)
+	mparser.Syntax(doc)
+	transform.New().Run(doc)
 
 	rfcdata := markdown.Render(doc, renderer)
 
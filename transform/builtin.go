@@ -0,0 +1,104 @@
+package transform
+
+import (
+	"bytes"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/mast"
+	"github.com/mmarkdown/mmark/mparser"
+)
+
+// Bibliography adds the normative and informative bibliography to the back matter of doc. If there
+// is no back matter nothing is added.
+func Bibliography(doc ast.Node) {
+	where := mparser.NodeBackMatter(doc)
+	if where == nil {
+		return
+	}
+
+	norm, inform := mparser.CitationToBibliography(doc)
+	if norm != nil {
+		ast.AppendChild(where, norm)
+	}
+	if inform != nil {
+		ast.AppendChild(where, inform)
+	}
+}
+
+// Index adds the document index to the end of doc.
+func Index(doc ast.Node) {
+	idx := mparser.IndexToDocumentIndex(doc)
+	if idx == nil {
+		return
+	}
+
+	ast.AppendChild(doc, idx)
+}
+
+// HeadingIDs makes the heading IDs in doc unique, by adding a "-N" suffix to the IDs that are seen
+// more than once.
+func HeadingIDs(doc ast.Node) {
+	ids := map[string]int{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		heading, ok := node.(*ast.Heading)
+		if !ok || !entering || heading.HeadingID == "" {
+			return ast.GoToNext
+		}
+		heading.HeadingID = mast.UniqueID(ids, heading.HeadingID)
+		return ast.GoToNext
+	})
+}
+
+// NoComments removes the editorial comments from doc. Paragraphs that only held a comment are removed
+// as well.
+func NoComments(doc ast.Node) {
+	comments := []*mast.Comment{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if c, ok := node.(*mast.Comment); ok && entering {
+			comments = append(comments, c)
+			return ast.SkipChildren
+		}
+		return ast.GoToNext
+	})
+	for _, c := range comments {
+		parent := c.Parent
+		// Don't leave two spaces where the comment was.
+		prev, ok1 := ast.GetPrevNode(c).(*ast.Text)
+		next, ok2 := ast.GetNextNode(c).(*ast.Text)
+		if ok1 && ok2 && bytes.HasSuffix(prev.Literal, []byte(" ")) && bytes.HasPrefix(next.Literal, []byte(" ")) {
+			prev.Literal = prev.Literal[:len(prev.Literal)-1]
+		}
+		ast.RemoveFromTree(c)
+
+		if _, ok := parent.(*ast.Paragraph); !ok {
+			continue
+		}
+		empty := true
+		for _, child := range parent.GetChildren() {
+			if t, ok := child.(*ast.Text); !ok || len(bytes.TrimSpace(t.Literal)) > 0 {
+				empty = false
+			}
+		}
+		if empty {
+			ast.RemoveFromTree(parent)
+		}
+	}
+}
+
+// BCP14Class is the class added by BCP14 to strong elements containing a BCP 14 keyword.
+const BCP14Class = "bcp14"
+
+// BCP14 detects the BCP 14 (RFC 2119) keywords typeset as strong (**MUST**) and adds the BCP14Class
+// class to those nodes.
+func BCP14(doc ast.Node) {
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		strong, ok := node.(*ast.Strong)
+		if !ok || !entering {
+			return ast.GoToNext
+		}
+		if t, ok := ast.GetFirstChild(strong).(*ast.Text); ok && mast.Is2119(t.Literal) {
+			mast.AddClass(strong, BCP14Class)
+		}
+		return ast.GoToNext
+	})
+}
@@ -0,0 +1,103 @@
+// Package transform implements the optional transformation passes that are run over the AST after
+// parsing and before rendering. The mmark syntax itself is handled by the parser, see mparser.Syntax.
+package transform
+
+import (
+	"fmt"
+
+	"github.com/gomarkdown/markdown/ast"
+)
+
+// Transform is a transformation pass over the AST.
+type Transform interface {
+	Transform(doc ast.Node)
+}
+
+// Func is an adapter to allow the use of ordinary functions as a Transform.
+type Func func(doc ast.Node)
+
+// Transform calls f(doc).
+func (f Func) Transform(doc ast.Node) { f(doc) }
+
+type pass struct {
+	name     string
+	t        Transform
+	disabled bool
+}
+
+// Registry is an ordered list of named transformation passes.
+type Registry struct {
+	passes []*pass
+}
+
+// New returns a registry with the built-in passes registered: "heading-ids", "bcp14",
+// "no-comments", "bibliography" and "index", in that order. All are enabled, except "no-comments".
+func New() *Registry {
+	r := &Registry{}
+	r.Register("heading-ids", Func(HeadingIDs))
+	r.Register("bcp14", Func(BCP14))
+	r.Register("no-comments", Func(NoComments))
+	r.Disable("no-comments")
+	r.Register("bibliography", Func(Bibliography))
+	r.Register("index", Func(Index))
+	return r
+}
+
+// Register adds the pass t under name to the end of r. If a pass with name already exists, it is
+// replaced in place.
+func (r *Registry) Register(name string, t Transform) {
+	if p := r.lookup(name); p != nil {
+		p.t = t
+		return
+	}
+	r.passes = append(r.passes, &pass{name: name, t: t})
+}
+
+// Enable enables the pass name.
+func (r *Registry) Enable(name string) error { return r.set(name, false) }
+
+// Disable disables the pass name, it will not be run.
+func (r *Registry) Disable(name string) error { return r.set(name, true) }
+
+func (r *Registry) set(name string, disabled bool) error {
+	p := r.lookup(name)
+	if p == nil {
+		return fmt.Errorf("unknown transformation pass %q", name)
+	}
+	p.disabled = disabled
+	return nil
+}
+
+func (r *Registry) lookup(name string) *pass {
+	for _, p := range r.passes {
+		if p.name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// Names returns the names of all passes in the order in which they are run.
+func (r *Registry) Names() []string {
+	names := make([]string, len(r.passes))
+	for i, p := range r.passes {
+		names[i] = p.name
+	}
+	return names
+}
+
+// Run runs all enabled passes over doc, in order.
+func (r *Registry) Run(doc ast.Node) {
+	for _, p := range r.passes {
+		if p.disabled {
+			continue
+		}
+		p.t.Transform(doc)
+	}
+}
+
+// Default is the default registry, containing the built-in passes.
+var Default = New()
+
+// Register adds the pass t under name to the default registry.
+func Register(name string, t Transform) { Default.Register(name, t) }
@@ -0,0 +1,86 @@
+package transform
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/mast"
+	"github.com/mmarkdown/mmark/mparser"
+)
+
+func TestRegistry(t *testing.T) {
+	r := New()
+	seen := []string{}
+	r.Register("custom", Func(func(doc ast.Node) { seen = append(seen, "custom") }))
+	r.Register("index", Func(func(doc ast.Node) { seen = append(seen, "index") }))
+
+	want := []string{"heading-ids", "bcp14", "no-comments", "bibliography", "index", "custom"}
+	if got := r.Names(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+
+	if err := r.Disable("custom"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Disable("does-not-exist"); err == nil {
+		t.Errorf("expected error for unknown pass")
+	}
+
+	r.Run(&ast.Document{})
+	if want := []string{"index"}; !reflect.DeepEqual(seen, want) {
+		t.Errorf("want %v, got %v", want, seen)
+	}
+}
+
+func TestHeadingIDs(t *testing.T) {
+	doc := markdown.Parse([]byte("# Intro\n\n# Intro\n\n# Intro\n"), parser.NewWithExtensions(parser.CommonExtensions|parser.AutoHeadingIDs))
+	HeadingIDs(doc)
+
+	got := []string{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if h, ok := node.(*ast.Heading); ok && entering {
+			got = append(got, h.HeadingID)
+		}
+		return ast.GoToNext
+	})
+	want := []string{"intro", "intro-1", "intro-2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestBCP14(t *testing.T) {
+	doc := markdown.Parse([]byte("You **MUST** do this, but **really** not that.\n"), parser.New())
+	BCP14(doc)
+
+	got := []bool{}
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if s, ok := node.(*ast.Strong); ok && entering {
+			got = append(got, len(mast.Classes(s)) == 1 && mast.Classes(s)[0] == BCP14Class)
+		}
+		return ast.GoToNext
+	})
+	if want := []bool{true, false}; !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestNoComments(t *testing.T) {
+	in := "Text [[ alice: fix *this* ]] here.\n\n[[ bob: a block comment ]]\n\nA [[plain]] one.\n"
+	doc := markdown.Parse([]byte(in), parser.New())
+	mparser.Syntax(doc)
+	NoComments(doc)
+	if n := len(doc.GetChildren()); n != 2 {
+		t.Fatalf("want 2 paragraphs, got %d", n)
+	}
+	text := ""
+	for _, child := range doc.GetChildren()[0].GetChildren() {
+		text += string(child.AsLeaf().Literal)
+	}
+	if want := "Text here."; text != want {
+		t.Errorf("want %q, got %q", want, text)
+	}
+}
@@ -22,9 +22,9 @@ func (r *Renderer) bibliography(w io.Writer, node *mast.Bibliography, entering b
 
 	switch node.Type {
 	case ast.CitationTypeInformative:
-		r.outs(w, `<references><name>Informative References</name>`)
+		r.outs(w, `<references><name>`+EscapeHTMLString(r.opts.Language.InformativeReferences)+`</name>`)
 	case ast.CitationTypeNormative:
-		r.outs(w, `<references><name>Normative References</name>`)
+		r.outs(w, `<references><name>`+EscapeHTMLString(r.opts.Language.NormativeReferences)+`</name>`)
 	}
 	r.cr(w)
 }
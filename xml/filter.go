@@ -0,0 +1,224 @@
+package xml
+
+import (
+	"log"
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/mast"
+)
+
+// Elements maps each RFC 7991 element to the attributes allowed on it, this is derived from
+// schema/rfc7991.rng. The xml:base, xml:lang and pn attributes are left out. The markers attribute on
+// sourcecode is newer than RFC 7991, but xml2rfc supports it.
+var Elements = map[string][]string{
+	"abstract":         {"anchor"},
+	"address":          nil,
+	"annotation":       nil,
+	"area":             nil,
+	"artwork":          {"align", "alt", "anchor", "height", "name", "originalSrc", "src", "type", "width"},
+	"aside":            {"anchor"},
+	"author":           {"asciiFullname", "asciiInitials", "asciiSurname", "fullname", "initials", "role", "surname"},
+	"back":             nil,
+	"bcp14":            nil,
+	"blockquote":       {"anchor", "cite", "quotedFrom"},
+	"boilerplate":      nil,
+	"br":               nil,
+	"c":                nil,
+	"city":             {"ascii"},
+	"code":             {"ascii"},
+	"country":          {"ascii"},
+	"cref":             {"anchor", "display", "source"},
+	"date":             {"day", "month", "year"},
+	"dd":               {"anchor"},
+	"displayreference": {"target", "to"},
+	"dl":               {"anchor", "hanging", "spacing"},
+	"dt":               {"anchor"},
+	"em":               nil,
+	"email":            {"ascii"},
+	"eref":             {"target"},
+	"facsimile":        nil,
+	"figure":           {"align", "alt", "anchor", "height", "originalSrc", "src", "suppress-title", "title", "width"},
+	"format":           {"octets", "target", "type"},
+	"front":            nil,
+	"iref":             {"item", "primary", "subitem"},
+	"keyword":          nil,
+	"li":               {"anchor"},
+	"link":             {"href", "rel"},
+	"list":             {"counter", "hangIndent", "style"},
+	"middle":           nil,
+	"name":             {"slugifiedName"},
+	"note":             {"removeInRFC", "title"},
+	"ol":               {"anchor", "group", "spacing", "start", "type"},
+	"organization":     {"abbrev", "ascii"},
+	"phone":            nil,
+	"postal":           nil,
+	"postalLine":       {"ascii"},
+	"postamble":        nil,
+	"preamble":         nil,
+	"refcontent":       nil,
+	"reference":        {"anchor", "quoteTitle", "target"},
+	"referencegroup":   {"anchor"},
+	"references":       {"anchor", "title"},
+	"region":           {"ascii"},
+	"relref":           {"derivedContent", "derivedLink", "displayFormat", "relative", "section", "target"},
+	"rfc":              {"category", "consensus", "docName", "expiresDate", "indexInclude", "ipr", "iprExtract", "mode", "number", "obsoletes", "prepTime", "scripts", "seriesNo", "sortRefs", "submissionType", "symRefs", "tocDepth", "tocInclude", "updates", "version"},
+	"section":          {"anchor", "numbered", "removeInRFC", "title", "toc"},
+	"seriesInfo":       {"asciiName", "asciiValue", "name", "status", "stream", "value"},
+	"sourcecode":       {"anchor", "markers", "name", "originalSrc", "src", "type"},
+	"spanx":            {"style"},
+	"street":           {"ascii"},
+	"strong":           nil,
+	"sub":              nil,
+	"sup":              nil,
+	"t":                {"anchor", "hangText", "keepWithNext", "keepWithPrevious"},
+	"table":            {"anchor"},
+	"tbody":            {"anchor"},
+	"td":               {"align", "anchor", "colspan", "rowspan"},
+	"texttable":        {"align", "anchor", "style", "suppress-title", "title"},
+	"tfoot":            {"anchor"},
+	"th":               {"align", "anchor", "colspan", "rowspan"},
+	"thead":            {"anchor"},
+	"title":            {"abbrev", "ascii"},
+	"tr":               {"anchor"},
+	"tt":               nil,
+	"ttcol":            {"align", "width"},
+	"ul":               {"anchor", "bare", "empty", "spacing"},
+	"uri":              nil,
+	"vspace":           {"blankLines"},
+	"workgroup":        nil,
+	"xref":             {"derivedContent", "derivedLink", "format", "pageno", "relative", "section", "sectionFormat", "target"},
+}
+
+var filterFunc mast.FilterFunc = func(s string) bool {
+	switch s {
+	case "id": // will translate to anchor so OK.
+		return true
+	case "class": // there are no classes
+		return false
+	case "style": // style has been deprecated in 7991
+		return false
+	}
+
+	// l33t data- HTML5 attributes
+	if strings.HasPrefix(s, "data-") {
+		return false
+	}
+
+	return true
+}
+
+// AttributeFilter is the default attribute filter for the XML3 renderer. It drops class, style
+// and data- attributes and any attribute that is not allowed on element according to Elements.
+func AttributeFilter(element, key string) bool {
+	if !filterFunc(key) {
+		return false
+	}
+	return Allowed(Elements, element, key)
+}
+
+// Allowed returns true if key is allowed on element according to elements. The key "id" is checked
+// as "anchor". If element isn't found in elements, all keys are allowed.
+func Allowed(elements map[string][]string, element, key string) bool {
+	attrs, ok := elements[element]
+	if !ok {
+		return true
+	}
+	if key == "id" {
+		key = "anchor"
+	}
+	for _, a := range attrs {
+		if a == key {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeElements returns the XML element(s) node will be rendered as. It returns nil when node
+// isn't rendered as an element that can carry block level attributes.
+func nodeElements(node ast.Node) []string {
+	switch node := node.(type) {
+	case *ast.Heading:
+		if node.IsSpecial {
+			if IsAbstract(node.Literal) {
+				return []string{"abstract"}
+			}
+			return []string{"note"}
+		}
+		return []string{"section"}
+	case *ast.Paragraph:
+		return []string{"t"}
+	case *ast.List:
+		if node.ListFlags&ast.ListTypeOrdered != 0 {
+			return []string{"ol"}
+		}
+		if node.ListFlags&ast.ListTypeDefinition != 0 {
+			return []string{"dl"}
+		}
+		return []string{"ul"}
+	case *ast.CodeBlock:
+		if node.Info != nil {
+			return []string{"sourcecode"}
+		}
+		return []string{"artwork"}
+	case *ast.Image, *ast.MathBlock:
+		return []string{"artwork"}
+	case *ast.CaptionFigure:
+		return []string{"figure"}
+	case *ast.Table:
+		return []string{"table"}
+	case *ast.TableCell:
+		if node.IsHeader {
+			return []string{"th"}
+		}
+		return []string{"td"}
+	case *ast.BlockQuote:
+		return []string{"blockquote"}
+	case *ast.Aside:
+		return []string{"aside"}
+	}
+	return nil
+}
+
+// attributeFilter returns a mast.FilterFunc that checks the attributes of node with the attribute filter
+// from the options. Attributes that are dropped, but aren't HTML attributes, are logged.
+func (r *Renderer) attributeFilter(node ast.Node) mast.FilterFunc {
+	return ElementFilter(nodeElements(node), r.opts.AttributeFilter, filterFunc)
+}
+
+// ElementFilter returns a mast.FilterFunc that allows a key if filter allows it on any of the elements. If
+// the key is dropped and warn returns true for it, a warning is logged. When a node is rendered as more
+// than one element, ElementAttrs selects the attributes of each element.
+func ElementFilter(elements []string, filter mast.ElementFilterFunc, warn mast.FilterFunc) mast.FilterFunc {
+	return func(key string) bool {
+		if len(elements) == 0 {
+			return filter("", key)
+		}
+		for _, e := range elements {
+			if filter(e, key) {
+				return true
+			}
+		}
+		if warn(key) {
+			log.Printf("Attribute %q not allowed on <%s>, dropping", key, elements[0])
+		}
+		return false
+	}
+}
+
+// ElementAttrs returns the attributes from attrs, as returned by html.BlockAttrs, that filter allows on
+// element.
+func ElementAttrs(element string, attrs []string, filter mast.ElementFilterFunc) []string {
+	allowed := []string{}
+	for _, a := range attrs {
+		key := a
+		if i := strings.Index(a, "="); i > 0 {
+			key = a[:i]
+		}
+		if filter(element, key) {
+			allowed = append(allowed, a)
+		}
+	}
+	return allowed
+}
@@ -0,0 +1,24 @@
+package xml
+
+import "testing"
+
+func TestAttributeFilter(t *testing.T) {
+	tests := []struct {
+		element, key string
+		want         bool
+	}{
+		{"blockquote", "id", true},
+		{"blockquote", "quotedFrom", true},
+		{"blockquote", "title", false},
+		{"ul", "empty", true},
+		{"ul", "class", false},
+		{"ul", "style", false},
+		{"t", "data-x", false},
+		{"unknown", "foo", true},
+	}
+	for _, tc := range tests {
+		if got := AttributeFilter(tc.element, tc.key); got != tc.want {
+			t.Errorf("want %t, got %t, for %s on <%s>", tc.want, got, tc.key, tc.element)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package xml
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"strconv"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/mmarkdown/mmark/mast"
+)
+
+// FootnoteStyle tells how footnotes are rendered, as neither RFC 7991 nor RFC 7749 has them.
+type FootnoteStyle int
+
+const (
+	FootnoteNone   FootnoteStyle = iota // Footnotes are discarded.
+	FootnoteNotes                       // A "[N]" link to a generated Footnotes section, which links back to the sections.
+	FootnoteCref                        // The footnote becomes a <cref> editorial comment.
+	FootnoteInline                      // The footnote is put inline, between parentheses.
+)
+
+// FootnoteStyles maps the names of the footnote styles to their FootnoteStyle.
+var FootnoteStyles = map[string]FootnoteStyle{
+	"none":   FootnoteNone,
+	"notes":  FootnoteNotes,
+	"cref":   FootnoteCref,
+	"inline": FootnoteInline,
+}
+
+// FootnoteID returns the anchor of the footnote with number n.
+func FootnoteID(n int) string { return "fn-" + strconv.Itoa(n) }
+
+// FootnoteNumber returns the number of the footnote item in the list of footnotes.
+func FootnoteNumber(item *ast.ListItem) int {
+	for i, child := range item.Parent.GetChildren() {
+		if child == item {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// FootnoteContent returns the list item holding the text of the footnote link refers to. Only the
+// first reference to a footnote has its text, so it is looked up in the footnote list by number.
+func FootnoteContent(link *ast.Link) ast.Node {
+	root := ast.Node(link)
+	for root.GetParent() != nil {
+		root = root.GetParent()
+	}
+	for _, child := range root.GetChildren() {
+		if list, ok := child.(*ast.List); ok && list.IsFootnotesList {
+			if items := list.GetChildren(); link.NoteID > 0 && link.NoteID <= len(items) {
+				return items[link.NoteID-1]
+			}
+		}
+	}
+	return link.Footnote
+}
+
+// FootnoteOpen returns the opening parenthesis for an inline footnote, with a space if the text
+// before it doesn't end in one.
+func FootnoteOpen(link *ast.Link) string {
+	if t, ok := ast.GetPrevNode(link).(*ast.Text); ok && bytes.HasSuffix(t.Literal, []byte(" ")) {
+		return "("
+	}
+	return " ("
+}
+
+// FootnoteParagraphs makes sure the content of the footnote items in doc is in paragraphs; inline
+// footnotes, i.e. "^[note]", don't have them. It should be called before rendering footnotes with
+// FootnoteNotes.
+func FootnoteParagraphs(doc ast.Node) {
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if item, ok := node.(*ast.ListItem); ok && entering && item.RefLink != nil {
+			footnoteParagraphs(item)
+			return ast.SkipChildren
+		}
+		return ast.GoToNext
+	})
+}
+
+func footnoteParagraphs(item *ast.ListItem) {
+	children := []ast.Node{}
+	var para *ast.Paragraph
+	for _, child := range item.GetChildren() {
+		if !isInline(child) {
+			children = append(children, child)
+			para = nil
+			continue
+		}
+		if para == nil {
+			para = &ast.Paragraph{}
+			para.Parent = item
+			children = append(children, para)
+		}
+		child.SetParent(para)
+		para.Children = append(para.Children, child)
+	}
+	item.Children = children
+}
+
+// FootnoteWalk calls fn for the inline content of the footnote item, the paragraphs are separated by
+// a space. Other blocks can't be put inline and are dropped with a warning.
+func FootnoteWalk(w io.Writer, item ast.Node, fn ast.NodeVisitorFunc) {
+	for i, child := range item.GetChildren() {
+		children := []ast.Node{child}
+		if _, ok := child.(*ast.Paragraph); ok {
+			children = child.GetChildren()
+			if i > 0 {
+				io.WriteString(w, " ")
+			}
+		} else if !isInline(child) {
+			log.Printf("Footnote contains a block that can't be put inline, dropping it")
+			continue
+		}
+		for _, c := range children {
+			ast.WalkFunc(c, fn)
+		}
+	}
+}
+
+// isInline returns true if node is an inline element.
+func isInline(node ast.Node) bool {
+	switch node.(type) {
+	case *ast.Emph, *ast.Strong, *ast.Del, *ast.Link, *ast.Image, *ast.Subscript, *ast.Superscript:
+		return true
+	case *ast.CodeBlock, *ast.HTMLBlock, *ast.MathBlock, *ast.HorizontalRule:
+		return false
+	}
+	return node.AsLeaf() != nil
+}
+
+func (r *Renderer) footnoteLink(w io.Writer, link *ast.Link) {
+	switch r.opts.Footnotes {
+	case FootnoteCref:
+		r.outs(w, "<cref>")
+		FootnoteWalk(w, FootnoteContent(link), func(node ast.Node, entering bool) ast.WalkStatus {
+			return r.RenderNode(w, node, entering)
+		})
+		r.outs(w, "</cref>")
+	case FootnoteInline:
+		r.outs(w, FootnoteOpen(link))
+		FootnoteWalk(w, FootnoteContent(link), func(node ast.Node, entering bool) ast.WalkStatus {
+			return r.RenderNode(w, node, entering)
+		})
+		r.outs(w, ")")
+	case FootnoteNotes:
+		if r.footnoteSections == nil {
+			r.footnoteSections = map[int]string{}
+		}
+		if _, ok := r.footnoteSections[link.NoteID]; !ok && r.section != nil {
+			if id := mast.Attribute(r.section, "id"); id != nil {
+				r.footnoteSections[link.NoteID] = string(id)
+			}
+		}
+		n := strconv.Itoa(link.NoteID)
+		r.outs(w, `<xref target="`+FootnoteID(link.NoteID)+`">[`+n+`]</xref>`)
+	}
+}
+
+// footnotes opens the Footnotes section, it is closed as any other section.
+func (r *Renderer) footnotes(w io.Writer, node *ast.Footnotes) {
+	if r.opts.Footnotes != FootnoteNotes {
+		return
+	}
+	r.sectionClose(w, &ast.Heading{Level: 1})
+	r.cr(w)
+	r.outTag(w, "<section", []string{`anchor="` + r.ensureUniqueHeadingID("footnotes") + `"`})
+	r.outs(w, "<name>"+EscapeHTMLString(r.opts.Language.Footnotes)+"</name>")
+	r.cr(w)
+}
+
+func (r *Renderer) footnoteList(w io.Writer, list *ast.List, entering bool) ast.WalkStatus {
+	if r.opts.Footnotes != FootnoteNotes {
+		return ast.SkipChildren
+	}
+	r.outOneOfCr(w, entering, "<dl>", "</dl>")
+	return ast.GoToNext
+}
+
+func (r *Renderer) footnoteItem(w io.Writer, item *ast.ListItem, entering bool) {
+	if !entering {
+		r.outs(w, "</dd>")
+		r.cr(w)
+		return
+	}
+	n := FootnoteNumber(item)
+	r.outs(w, `<dt anchor="`+FootnoteID(n)+`">[`+strconv.Itoa(n)+"]</dt>")
+	r.cr(w)
+	r.outs(w, "<dd>")
+}
+
+// footnoteBack writes the link from the last paragraph of a footnote back to the section it is
+// referenced from.
+func (r *Renderer) footnoteBack(w io.Writer, para *ast.Paragraph) {
+	item, ok := para.Parent.(*ast.ListItem)
+	if !ok || item.RefLink == nil || ast.GetNextNode(para) != nil {
+		return
+	}
+	if id, ok := r.footnoteSections[FootnoteNumber(item)]; ok {
+		r.outs(w, ` (<xref target="`)
+		html.EscapeHTML(w, []byte(id))
+		r.outs(w, `"/>)`)
+	}
+}
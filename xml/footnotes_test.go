@@ -0,0 +1,51 @@
+package xml
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+func TestFootnotes(t *testing.T) {
+	in := "# One\n\nText[^a] and ^[inline *note*].\n\n# Two\n\nAgain[^a].\n\n[^a]: A note.\n"
+	tests := []struct {
+		style FootnoteStyle
+		want  []string
+	}{
+		{FootnoteNone, []string{
+			`<t>Text and .</t>`,
+			`<t>Again.</t>`,
+		}},
+		{FootnoteNotes, []string{
+			`<t>Text<xref target="fn-1">[1]</xref> and <xref target="fn-2">[2]</xref>.</t>`,
+			`<section anchor="footnotes"><name>Footnotes</name>`,
+			`<dt anchor="fn-1">[1]</dt>` + "\n" + `<dd><t>A note. (<xref target="one"/>)</t>`,
+			`<dd><t>inline <em>note</em> (<xref target="one"/>)</t>`,
+		}},
+		{FootnoteCref, []string{
+			`<t>Text<cref>A note.</cref> and <cref>inline <em>note</em></cref>.</t>`,
+			`<t>Again<cref>A note.</cref>.</t>`,
+		}},
+		{FootnoteInline, []string{
+			`<t>Text (A note.) and (inline <em>note</em>).</t>`,
+			`<t>Again (A note.).</t>`,
+		}},
+	}
+	for _, tc := range tests {
+		p := parser.NewWithExtensions(parser.CommonExtensions | parser.AutoHeadingIDs | parser.Footnotes)
+		doc := markdown.Parse([]byte(in), p)
+		FootnoteParagraphs(doc)
+		r := NewRenderer(RendererOptions{Flags: XMLFragment, Footnotes: tc.style})
+		got := string(markdown.Render(doc, r))
+		for _, want := range tc.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("want %q in output, got %s, for %d", want, got, tc.style)
+			}
+		}
+		if tc.style != FootnoteNotes && strings.Contains(got, "ootnote") {
+			t.Errorf("want no footnotes section, got %s, for %d", got, tc.style)
+		}
+	}
+}
@@ -99,22 +99,7 @@ func (r *Renderer) sectionClose(w io.Writer, new *ast.Heading) {
 }
 
 func (r *Renderer) ensureUniqueHeadingID(id string) string {
-	for count, found := r.headingIDs[id]; found; count, found = r.headingIDs[id] {
-		tmp := fmt.Sprintf("%s-%d", id, count+1)
-
-		if _, tmpFound := r.headingIDs[tmp]; !tmpFound {
-			r.headingIDs[id] = count + 1
-			id = tmp
-		} else {
-			id = id + "-1"
-		}
-	}
-
-	if _, found := r.headingIDs[id]; !found {
-		r.headingIDs[id] = 0
-	}
-
-	return id
+	return mast.UniqueID(r.headingIDs, id)
 }
 
 // IsAbstract returns if word is equal to abstract.
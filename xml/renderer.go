@@ -3,11 +3,14 @@ package xml
 import (
 	"fmt"
 	"io"
+	"log"
 	"strconv"
 	"strings"
 
 	"github.com/gomarkdown/markdown/ast"
 	"github.com/gomarkdown/markdown/html"
+	"github.com/mmarkdown/mmark/lang"
+	"github.com/mmarkdown/mmark/latex"
 	"github.com/mmarkdown/mmark/mast"
 )
 
@@ -42,6 +45,25 @@ type RendererOptions struct {
 
 	// Generator is a comment that is inserted in the generated XML to show what rendered it.
 	Generator string
+
+	// AttributeFilter decides which block level attributes are allowed on an element. If nil,
+	// AttributeFilter is used.
+	AttributeFilter mast.ElementFilterFunc
+
+	// Renderers holds the functions that render specific node types, these are consulted
+	// before RenderNodeHook.
+	Renderers mast.Renderers
+
+	// UnknownNode tells what to do with node types the renderer doesn't know.
+	UnknownNode mast.UnknownNode
+
+	// Language holds the labels for the generated headings, its Tag is used for xml:lang. If Tag is
+	// empty, English is used.
+	Language lang.Lang
+
+	// Footnotes tells how footnotes are rendered, FootnoteNone (the default) discards them. For the
+	// other styles the parser must not skip the footnote list.
+	Footnotes FootnoteStyle
 }
 
 // Renderer implements Renderer interface for IETF XMLv3 output. See RFC 7991.
@@ -51,28 +73,13 @@ type Renderer struct {
 	documentMatter ast.DocumentMatters // keep track of front/main/back matter
 	section        *ast.Heading        // current open section
 	title          bool                // did we output a title block
-	filter         mast.FilterFunc     // filter for attributes.
 
 	// Track heading IDs to prevent ID collision in a single generation.
 	headingIDs map[string]int
-}
 
-var filterFunc mast.FilterFunc = func(s string) bool {
-	switch s {
-	case "id": // will translate to anchor so OK.
-		return true
-	case "class": // there are no classes
-		return false
-	case "style": // style has been deprecated in 7991
-		return false
-	}
-
-	// l33t data- HTML5 attributes
-	if strings.HasPrefix(s, "data-") {
-		return false
-	}
+	footnoteSections map[int]string // footnote number -> anchor of the section it is first referenced in
 
-	return true
+	err error // set when rendering is stopped because of an unknown node
 }
 
 // NewRenderer creates and configures an Renderer object, which satisfies the Renderer interface.
@@ -81,7 +88,13 @@ func NewRenderer(opts RendererOptions) *Renderer {
 	if opts.Generator == "" {
 		opts.Generator = Generator
 	}
-	return &Renderer{opts: opts, headingIDs: make(map[string]int), filter: filterFunc}
+	if opts.AttributeFilter == nil {
+		opts.AttributeFilter = AttributeFilter
+	}
+	if opts.Language.Tag == "" {
+		opts.Language = lang.New("en")
+	}
+	return &Renderer{opts: opts, headingIDs: make(map[string]int)}
 }
 
 func (r *Renderer) text(w io.Writer, text *ast.Text) {
@@ -111,7 +124,7 @@ func (r *Renderer) strong(w io.Writer, node *ast.Strong, entering bool) {
 	// *iff* we have a text node as a child *and* that text is 2119, we output bcp14 tags, otherwise just string.
 	text := ast.GetFirstChild(node)
 	if t, ok := text.(*ast.Text); ok {
-		if Is2119(t.Literal) {
+		if mast.Is2119(t.Literal) {
 			r.outOneOf(w, entering, "<bcp14>", "</bcp14>")
 			return
 		}
@@ -213,6 +226,10 @@ func (r *Renderer) citation(w io.Writer, node *ast.Citation, entering bool) {
 		}
 
 		attr := []string{fmt.Sprintf(`target="%s"`, c)}
+		if loc := mast.CitationLocator(node.Suffix[i]); loc != nil {
+			// An appendix is recognized by its letter, i.e. section="B".
+			attr = append(attr, fmt.Sprintf(`section="%s"`, loc.Section), fmt.Sprintf(`sectionFormat="%s"`, loc.Format))
+		}
 		r.outTag(w, "<xref", attr)
 		r.outs(w, "</xref>")
 	}
@@ -240,6 +257,7 @@ func (r *Renderer) paragraphExit(w io.Writer, para *ast.Paragraph) {
 	if _, ok := para.Parent.(*ast.CaptionFigure); ok {
 		return
 	}
+	r.footnoteBack(w, para)
 	r.outs(w, "</t>")
 	r.cr(w)
 }
@@ -253,9 +271,6 @@ func (r *Renderer) paragraph(w io.Writer, para *ast.Paragraph, entering bool) {
 }
 
 func (r *Renderer) listEnter(w io.Writer, nodeData *ast.List) {
-	if nodeData.IsFootnotesList {
-		return
-	}
 	r.cr(w)
 
 	openTag := "<ul"
@@ -274,9 +289,6 @@ func (r *Renderer) listEnter(w io.Writer, nodeData *ast.List) {
 }
 
 func (r *Renderer) listExit(w io.Writer, list *ast.List) {
-	if list.IsFootnotesList {
-		return
-	}
 	closeTag := "</ul>"
 	if list.ListFlags&ast.ListTypeOrdered != 0 {
 		closeTag = "</ol>"
@@ -295,10 +307,6 @@ func (r *Renderer) listExit(w io.Writer, list *ast.List) {
 	case *ast.Document, *ast.BlockQuote, *ast.Aside:
 		r.cr(w)
 	}
-
-	if list.IsFootnotesList {
-		r.outs(w, "\n</div>\n")
-	}
 }
 
 func (r *Renderer) list(w io.Writer, list *ast.List, entering bool) {
@@ -310,10 +318,6 @@ func (r *Renderer) list(w io.Writer, list *ast.List, entering bool) {
 }
 
 func (r *Renderer) listItemEnter(w io.Writer, listItem *ast.ListItem) {
-	if listItem.RefLink != nil { //footnotes
-		return
-	}
-
 	openTag := "<li>"
 	if listItem.ListFlags&ast.ListTypeDefinition != 0 {
 		openTag = "<dd>"
@@ -325,10 +329,6 @@ func (r *Renderer) listItemEnter(w io.Writer, listItem *ast.ListItem) {
 }
 
 func (r *Renderer) listItemExit(w io.Writer, listItem *ast.ListItem) {
-	if listItem.RefLink != nil {
-		return
-	}
-
 	closeTag := "</li>"
 	if listItem.ListFlags&ast.ListTypeDefinition != 0 {
 		closeTag = "</dd>"
@@ -368,6 +368,52 @@ func (r *Renderer) codeBlock(w io.Writer, codeBlock *ast.CodeBlock) {
 	r.cr(w)
 }
 
+func (r *Renderer) artSet(w io.Writer, set *mast.ArtSet) {
+	for _, err := range CheckSVG(set.SVG) {
+		log.Printf("SVG %q doesn't conform to RFC 7996: %s", set.Src, err)
+	}
+	art, _ := ast.GetFirstChild(set).(*ast.CodeBlock)
+	r.cr(w)
+	attr := []string{}
+	if art != nil && mast.Attribute(art, "id") != nil {
+		attr = append(attr, `anchor="`+EscapeHTMLString(string(mast.Attribute(art, "id")))+`"`)
+	}
+	r.outTag(w, "<artset", attr)
+	r.cr(w)
+	attr = []string{`type="svg"`}
+	if len(set.Alt) > 0 {
+		attr = append(attr, `alt="`+EscapeHTMLString(string(set.Alt))+`"`)
+	}
+	r.outTag(w, "<artwork", attr)
+	r.cr(w)
+	r.out(w, set.SVG)
+	r.cr(w)
+	r.outs(w, "</artwork>")
+	r.cr(w)
+	if art != nil {
+		r.outs(w, `<artwork type="ascii-art">`)
+		html.EscapeHTML(w, art.Literal)
+		r.outs(w, "</artwork>")
+		r.cr(w)
+	}
+	r.outs(w, "</artset>")
+	r.cr(w)
+}
+
+// raw outputs the raw content if it is for RFC 7991 XML.
+func (r *Renderer) raw(w io.Writer, raw *mast.Raw) {
+	if raw.Format != "xml3" {
+		return
+	}
+	if raw.Block {
+		r.cr(w)
+	}
+	r.out(w, raw.Literal)
+	if raw.Block {
+		r.cr(w)
+	}
+}
+
 func (r *Renderer) tableCell(w io.Writer, tableCell *ast.TableCell, entering bool) {
 	if !entering {
 		r.outOneOf(w, tableCell.IsHeader, "</th>", "</td>")
@@ -385,6 +431,9 @@ func (r *Renderer) tableCell(w io.Writer, tableCell *ast.TableCell, entering boo
 	if align != "" {
 		mast.SetAttribute(tableCell, "align", []byte(align))
 	}
+	if tableCell.ColSpan > 1 {
+		mast.SetAttribute(tableCell, "colspan", []byte(strconv.Itoa(tableCell.ColSpan)))
+	}
 	if ast.GetPrevNode(tableCell) == nil {
 		r.cr(w)
 	}
@@ -407,6 +456,18 @@ func (r *Renderer) callout(w io.Writer, callout *ast.Callout) {
 	r.outs(w, "</em>")
 }
 
+func (r *Renderer) comment(w io.Writer, comment *mast.Comment, entering bool) {
+	if !entering {
+		r.outs(w, "</cref>")
+		return
+	}
+	attr := []string{}
+	if len(comment.Source) > 0 {
+		attr = append(attr, `source="`+EscapeHTMLString(string(comment.Source))+`"`)
+	}
+	r.outTag(w, "<cref", attr)
+}
+
 func (r *Renderer) crossReference(w io.Writer, cr *ast.CrossReference, entering bool) {
 	if entering {
 		r.outTag(w, "<xref", []string{"target=\"" + string(cr.Destination) + "\""})
@@ -433,6 +494,9 @@ func (r *Renderer) index(w io.Writer, index *ast.Index) {
 
 func (r *Renderer) link(w io.Writer, link *ast.Link, entering bool) {
 	if link.Footnote != nil {
+		if entering {
+			r.footnoteLink(w, link)
+		}
 		return
 	}
 	if !entering {
@@ -475,11 +539,32 @@ func (r *Renderer) code(w io.Writer, node *ast.Code) {
 	r.outs(w, "</tt>")
 }
 
-func (r *Renderer) mathBlock(w io.Writer, mathBlock *ast.MathBlock) {
+func (r *Renderer) math(w io.Writer, math *ast.Math) {
+	r.outs(w, "<tt>")
+	text, err := latex.Text(string(math.Literal), false)
+	if err != nil {
+		log.Printf("Failed to convert math %q: %s, using it as is", math.Literal, err)
+		text = string(math.Literal)
+	}
+	html.EscapeHTML(w, []byte(text))
+	r.outs(w, "</tt>")
+}
+
+func (r *Renderer) mathBlock(w io.Writer, mathBlock *ast.MathBlock, entering bool) {
+	if !entering {
+		return
+	}
 	r.outs(w, `<artwork type="math">`+"\n")
-	if r.opts.Comments != nil {
+	// Use ASCII art, because artwork in RFCs should be ASCII only.
+	art, err := latex.Art(string(mathBlock.Literal), true)
+	switch {
+	case err == nil:
+		html.EscapeHTML(w, []byte(art+"\n"))
+	case r.opts.Comments != nil:
+		log.Printf("Failed to convert math block: %s, using it as is", err)
 		EscapeHTMLCallouts(w, mathBlock.Literal, r.opts.Comments)
-	} else {
+	default:
+		log.Printf("Failed to convert math block: %s, using it as is", err)
 		html.EscapeHTML(w, mathBlock.Literal)
 	}
 	r.outs(w, `</artwork>`)
@@ -584,8 +669,16 @@ func (r *Renderer) blockQuote(w io.Writer, block *ast.BlockQuote, entering bool)
 
 // RenderNode renders a markdown node to XML.
 func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.WalkStatus {
+	if cell, ok := node.(*ast.TableCell); ok {
+		// RFC 7991 has no column widths, drop them without a warning.
+		mast.DeleteAttribute(cell, "width")
+	}
 
-	mast.AttributeFilter(node, r.filter)
+	mast.AttributeFilter(node, r.attributeFilter(node))
+
+	if fn := r.opts.Renderers.Lookup(node); fn != nil {
+		return fn(w, node, entering)
+	}
 
 	if r.opts.RenderNodeHook != nil {
 		status, didHandle := r.opts.RenderNodeHook(w, node, entering)
@@ -606,6 +699,8 @@ func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.Wal
 		r.bibliographyItem(w, node)
 	case *mast.DocumentIndex, *mast.IndexLetter, *mast.IndexItem, *mast.IndexSubItem, *mast.IndexLink:
 		// generated by xml2rfc, do nothing.
+	case *mast.TableOfContents:
+		// generated by xml2rfc, do nothing.
 	case *ast.Text:
 		r.text(w, node)
 	case *ast.Softbreak:
@@ -636,12 +731,28 @@ func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.Wal
 		r.htmlSpan(w, node) // only html comments are allowed.
 	case *ast.HTMLBlock:
 		// discard; we use these only for <references>.
+	case *ast.Footnotes:
+		if entering {
+			r.footnotes(w, node)
+		}
 	case *ast.List:
+		if node.IsFootnotesList {
+			return r.footnoteList(w, node, entering)
+		}
 		r.list(w, node, entering)
 	case *ast.ListItem:
+		if node.RefLink != nil {
+			r.footnoteItem(w, node, entering)
+			break
+		}
 		r.listItem(w, node, entering)
 	case *ast.CodeBlock:
 		r.codeBlock(w, node)
+	case *mast.ArtSet:
+		if entering {
+			r.artSet(w, node)
+		}
+		return ast.SkipChildren
 	case *ast.Caption:
 		// We do some funky node re-ordering for the caption so it is rendered in the correct
 		// spot. For some reason -- even when we call ast.RemoveFromTree -- we still end up
@@ -670,6 +781,10 @@ func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.Wal
 	case *ast.Aside:
 		tag := tagWithAttributes("<aside", html.BlockAttrs(node))
 		r.outOneOfCr(w, entering, tag, "</aside>")
+	case *mast.Comment:
+		r.comment(w, node, entering)
+	case *mast.Raw:
+		r.raw(w, node)
 	case *ast.CrossReference:
 		r.crossReference(w, node, entering)
 	case *ast.Index:
@@ -679,9 +794,7 @@ func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.Wal
 	case *ast.Link:
 		r.link(w, node, entering)
 	case *ast.Math:
-		r.outOneOf(w, true, "<tt>", "</tt>")
-		html.EscapeHTML(w, node.Literal)
-		r.outOneOf(w, false, "<tt>", "</tt>")
+		r.math(w, node)
 	case *ast.Image:
 		if r.opts.Flags&SkipImages != 0 {
 			return ast.SkipChildren
@@ -690,7 +803,7 @@ func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.Wal
 	case *ast.Code:
 		r.code(w, node)
 	case *ast.MathBlock:
-		r.mathBlock(w, node)
+		r.mathBlock(w, node, entering)
 	case *ast.Subscript:
 		r.outOneOf(w, true, "<sub>", "</sub>")
 		if entering {
@@ -704,11 +817,26 @@ func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.Wal
 		}
 		r.outOneOf(w, false, "<sup>", "</sup>")
 	default:
-		panic(fmt.Sprintf("Unknown node %T", node))
+		status, err := r.opts.UnknownNode.Unknown(node, entering)
+		if err != nil {
+			r.err = err
+		}
+		return status
 	}
 	return ast.GoToNext
 }
 
+// Register registers fn as the function that renders nodes of the same type as node.
+func (r *Renderer) Register(node ast.Node, fn mast.RenderFunc) {
+	if r.opts.Renderers == nil {
+		r.opts.Renderers = mast.Renderers{}
+	}
+	r.opts.Renderers.Register(node, fn)
+}
+
+// Err returns the error that stopped the rendering, if any.
+func (r *Renderer) Err() error { return r.err }
+
 // RenderHeader writes HTML document preamble and TOC if requested.
 func (r *Renderer) RenderHeader(w io.Writer, ast ast.Node) {
 	if r.opts.Flags&XMLFragment != 0 {
@@ -0,0 +1,84 @@
+package xml
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/mast"
+)
+
+type custom struct {
+	ast.Container
+}
+
+func customDoc() ast.Node {
+	doc := &ast.Document{}
+	c := &custom{}
+	ast.AppendChild(doc, c)
+	ast.AppendChild(c, &ast.Text{Leaf: ast.Leaf{Literal: []byte("text")}})
+	return doc
+}
+
+func TestRenderUnknownNode(t *testing.T) {
+	tests := []struct {
+		unknown mast.UnknownNode
+		want    string
+		err     bool
+	}{
+		{mast.UnknownChildren, "text", false},
+		{mast.UnknownSkip, "", false},
+		{mast.UnknownError, "", true},
+	}
+	for _, tc := range tests {
+		r := NewRenderer(RendererOptions{Flags: XMLFragment, UnknownNode: tc.unknown})
+		got := strings.TrimSpace(string(markdown.Render(customDoc(), r)))
+		if got != tc.want {
+			t.Errorf("want %q, got %q, for %d", tc.want, got, tc.unknown)
+		}
+		if (r.Err() != nil) != tc.err {
+			t.Errorf("want error %t, got %v, for %d", tc.err, r.Err(), tc.unknown)
+		}
+	}
+}
+
+func TestRenderRegister(t *testing.T) {
+	r := NewRenderer(RendererOptions{Flags: XMLFragment})
+	r.Register(&custom{}, func(w io.Writer, node ast.Node, entering bool) ast.WalkStatus {
+		if entering {
+			io.WriteString(w, "<custom/>")
+		}
+		return ast.SkipChildren
+	})
+	got := strings.TrimSpace(string(markdown.Render(customDoc(), r)))
+	if want := "<custom/>"; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestRenderComment(t *testing.T) {
+	doc := &ast.Document{}
+	para := &ast.Paragraph{}
+	c := &mast.Comment{Source: []byte("alice")}
+	ast.AppendChild(doc, para)
+	ast.AppendChild(para, c)
+	ast.AppendChild(c, &ast.Text{Leaf: ast.Leaf{Literal: []byte("fix this")}})
+
+	r := NewRenderer(RendererOptions{Flags: XMLFragment})
+	got := strings.TrimSpace(string(markdown.Render(doc, r)))
+	if want := `<t><cref source="alice">fix this</cref></t>`; got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestRenderMathBlock(t *testing.T) {
+	p := parser.NewWithExtensions(parser.CommonExtensions | parser.MathJax)
+	doc := markdown.Parse([]byte("$$\nx^2\n$$\n"), p)
+	got := string(markdown.Render(doc, NewRenderer(RendererOptions{Flags: XMLFragment})))
+	if n := strings.Count(got, `<artwork type="math">`); n != 1 {
+		t.Errorf("want 1 math artwork, got %d in %q", n, got)
+	}
+}
@@ -0,0 +1,160 @@
+package xml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// svgElement holds what is allowed on an SVG element.
+type svgElement struct {
+	presentation bool     // The presentation attributes, svgPresentation, are allowed.
+	attributes   []string // Other allowed attributes, besides the core ones in svgCore.
+	children     []string // Allowed child elements.
+}
+
+// svgElements maps each element of the SVG profile for RFCs (RFC 7996) to what is allowed on it, this
+// is derived from schema/SVG-1.2-RFC.rng.
+var svgElements = map[string]svgElement{
+	"a":              {true, []string{"xlink:actuate", "xlink:arcrole", "xlink:href", "xlink:role", "xlink:show", "xlink:title", "xlink:type", "requiredExtensions", "requiredFeatures", "requiredFonts", "requiredFormats", "systemLanguage", "target", "transform"}, []string{"circle", "defs", "desc", "ellipse", "g", "line", "linearGradient", "path", "polygon", "polyline", "radialGradient", "rect", "solidColor", "text", "textArea", "title", "tspan", "use"}},
+	"circle":         {true, []string{"cx", "cy", "r", "requiredExtensions", "requiredFeatures", "requiredFonts", "requiredFormats", "style", "systemLanguage", "transform"}, []string{"desc", "title"}},
+	"defs":           {true, nil, []string{"a", "circle", "defs", "desc", "ellipse", "g", "line", "linearGradient", "path", "polygon", "polyline", "radialGradient", "rect", "solidColor", "text", "textArea", "title", "use"}},
+	"desc":           {false, []string{"buffered-rendering", "display", "image-rendering", "requiredExtensions", "requiredFeatures", "requiredFonts", "requiredFormats", "shape-rendering", "systemLanguage", "text-rendering", "viewport-fill", "viewport-fill-opacity", "visibility"}, nil},
+	"ellipse":        {true, []string{"cx", "cy", "requiredExtensions", "requiredFeatures", "requiredFonts", "requiredFormats", "rx", "ry", "style", "systemLanguage", "transform"}, []string{"desc", "title"}},
+	"g":              {true, []string{"requiredExtensions", "requiredFeatures", "requiredFonts", "requiredFormats", "style", "systemLanguage", "transform", "visibility"}, []string{"a", "circle", "defs", "desc", "ellipse", "g", "line", "linearGradient", "path", "polygon", "polyline", "radialGradient", "rect", "solidColor", "text", "textArea", "title", "use"}},
+	"line":           {true, []string{"requiredExtensions", "requiredFeatures", "requiredFonts", "requiredFormats", "systemLanguage", "transform", "x1", "x2", "y1", "y2"}, []string{"desc", "title"}},
+	"linearGradient": {true, []string{"gradientUnits", "x1", "x2", "y1", "y2"}, []string{"desc", "title"}},
+	"path":           {true, []string{"d", "pathLength", "requiredExtensions", "requiredFeatures", "requiredFonts", "requiredFormats", "style", "systemLanguage", "transform"}, []string{"desc", "title"}},
+	"polygon":        {true, []string{"points", "requiredExtensions", "requiredFeatures", "requiredFonts", "requiredFormats", "style", "systemLanguage", "transform"}, []string{"desc", "title"}},
+	"polyline":       {true, []string{"points", "requiredExtensions", "requiredFeatures", "requiredFonts", "requiredFormats", "systemLanguage", "transform"}, []string{"desc", "title"}},
+	"radialGradient": {true, []string{"cx", "cy", "gradientUnits", "r"}, []string{"desc", "title"}},
+	"rect":           {true, []string{"height", "requiredExtensions", "requiredFeatures", "requiredFonts", "requiredFormats", "rx", "ry", "style", "systemLanguage", "transform", "width", "x", "y"}, []string{"desc", "title"}},
+	"solidColor":     {true, nil, []string{"desc", "title"}},
+	"stop":           {true, []string{"offset"}, []string{"desc", "title"}},
+	"svg":            {true, []string{"baseProfile", "contentScriptType", "height", "playbackOrder", "preserveAspectRatio", "snapshotTime", "timelineBegin", "version", "viewBox", "width", "zoomAndPan"}, []string{"a", "circle", "defs", "desc", "ellipse", "g", "line", "linearGradient", "path", "polygon", "polyline", "radialGradient", "rect", "solidColor", "text", "textArea", "title", "use"}},
+	"tbreak":         {false, []string{"requiredExtensions", "requiredFeatures", "requiredFonts", "requiredFormats", "systemLanguage"}, nil},
+	"text":           {true, []string{"requiredExtensions", "requiredFeatures", "requiredFonts", "requiredFormats", "rotate", "style", "systemLanguage", "transform", "x", "y"}, []string{"a", "desc", "title", "tspan"}},
+	"textArea":       {true, []string{"height", "requiredExtensions", "requiredFeatures", "requiredFonts", "requiredFormats", "systemLanguage", "transform", "width", "x", "y"}, []string{"a", "desc", "title", "tspan"}},
+	"title":          {false, []string{"buffered-rendering", "display", "image-rendering", "requiredExtensions", "requiredFeatures", "requiredFonts", "requiredFormats", "shape-rendering", "systemLanguage", "text-rendering", "viewport-fill", "viewport-fill-opacity", "visibility"}, nil},
+	"tspan":          {true, []string{"requiredExtensions", "requiredFeatures", "requiredFonts", "requiredFormats", "systemLanguage", "x", "y"}, []string{"a", "desc", "tbreak", "title", "tspan"}},
+	"use":            {true, []string{"xlink:actuate", "xlink:arcrole", "xlink:href", "xlink:role", "xlink:show", "xlink:title", "xlink:type", "requiredExtensions", "requiredFeatures", "requiredFonts", "requiredFormats", "systemLanguage", "transform", "x", "y"}, []string{"desc", "title"}},
+}
+
+// svgCore are the attributes allowed on all elements.
+var svgCore = []string{"about", "class", "content", "datatype", "id", "property", "rel", "resource", "rev", "role", "typeof", "xml:base", "xml:id", "xml:lang", "xml:space"}
+
+// svgPresentation are the presentation attributes, see svgElement.
+var svgPresentation = []string{"color", "color-rendering", "direction", "display-align", "fill", "fill-opacity", "fill-rule", "font-family", "font-size", "font-style", "font-variant", "font-weight", "line-increment", "solid-color", "solid-opacity", "stop-color", "stop-opacity", "stroke", "stroke-dasharray", "stroke-dashoffset", "stroke-linecap", "stroke-linejoin", "stroke-miterlimit", "stroke-opacity", "stroke-width", "text-align", "text-anchor", "unicode-bidi", "vector-effect"}
+
+// svgColors are the colors allowed in the color attributes, see svgColorAttributes.
+var svgColors = map[string]bool{"black": true, "white": true, "#000000": true, "#FFFFFF": true, "#ffffff": true, "inherit": true}
+
+// svgColorAttributes are the attributes that may only use the colors in svgColors, and "none" if the
+// value is true.
+var svgColorAttributes = map[string]bool{"fill": true, "viewport-fill": true, "stroke": false, "color": false, "solid-color": false, "stop-color": false}
+
+const (
+	svgNamespace   = "http://www.w3.org/2000/svg"
+	xlinkNamespace = "http://www.w3.org/1999/xlink"
+	xmlNamespace   = "http://www.w3.org/XML/1998/namespace"
+)
+
+// CheckSVG checks the SVG in data against the SVG profile for RFCs and returns the problems found:
+// elements, attributes and colors that are not allowed. Each problem is only reported once.
+func CheckSVG(data []byte) []error {
+	errs := []error{}
+	seen := map[string]bool{}
+	report := func(format string, a ...interface{}) {
+		msg := fmt.Sprintf(format, a...)
+		if !seen[msg] {
+			seen[msg] = true
+			errs = append(errs, fmt.Errorf("%s", msg))
+		}
+	}
+
+	stack := []string{}
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			report("invalid XML: %s", err)
+			break
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			name := tok.Name.Local
+			stack = append(stack, name)
+			if tok.Name.Space != svgNamespace {
+				report("element <%s> is not in the SVG namespace", name)
+				continue
+			}
+			el, ok := svgElements[name]
+			if !ok {
+				report("element <%s> is not allowed", name)
+				continue
+			}
+			if len(stack) == 1 && name != "svg" {
+				report("root element must be <svg>, not <%s>", name)
+			}
+			if len(stack) > 1 {
+				if parent, ok := svgElements[stack[len(stack)-2]]; ok && !contains(parent.children, name) {
+					report("element <%s> is not allowed in <%s>", name, stack[len(stack)-2])
+				}
+			}
+			for _, a := range tok.Attr {
+				attr := svgAttribute(a.Name)
+				switch {
+				case attr == "":
+					continue
+				case contains(svgCore, attr), contains(el.attributes, attr):
+				case el.presentation && contains(svgPresentation, attr):
+				default:
+					report("attribute %q is not allowed on <%s>", attr, name)
+					continue
+				}
+				if none, ok := svgColorAttributes[attr]; ok && !svgColors[a.Value] && !(none && a.Value == "none") {
+					report("color %q in attribute %q is not allowed, only black and white are", a.Value, attr)
+				}
+			}
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+	return errs
+}
+
+// svgAttribute returns the name of the attribute as used in svgElements, namespace declarations
+// return the empty string.
+func svgAttribute(name xml.Name) string {
+	switch name.Space {
+	case "":
+		if name.Local == "xmlns" {
+			return ""
+		}
+		return name.Local
+	case "xmlns":
+		return ""
+	case xlinkNamespace:
+		return "xlink:" + name.Local
+	case xmlNamespace, "xml":
+		return "xml:" + name.Local
+	}
+	return strings.TrimSuffix(name.Space, "/") + ":" + name.Local
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
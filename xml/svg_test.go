@@ -0,0 +1,27 @@
+package xml
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckSVG(t *testing.T) {
+	good := `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" version="1.2" viewBox="0 0 10 10">
+<g stroke="black" fill="none"><rect x="1" y="1" width="8" height="8"/><a xlink:href="#x"><text x="2" y="5">x</text></a></g>
+</svg>`
+	if errs := CheckSVG([]byte(good)); len(errs) != 0 {
+		t.Errorf("want no errors, got %v", errs)
+	}
+
+	bad := `<svg xmlns="http://www.w3.org/2000/svg"><rect fill="red" onclick="x()"/><image/><rect fill="red"/></svg>`
+	errs := CheckSVG([]byte(bad))
+	want := []string{`color "red"`, `attribute "onclick"`, `element <image>`}
+	if len(errs) != len(want) {
+		t.Fatalf("want %d errors, got %v", len(want), errs)
+	}
+	for i := range want {
+		if !strings.Contains(errs[i].Error(), want[i]) {
+			t.Errorf("want %q in error, got %q", want[i], errs[i])
+		}
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"io"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/gomarkdown/markdown/ast"
 	"github.com/gomarkdown/markdown/html"
@@ -36,7 +35,7 @@ func (r *Renderer) titleBlock(w io.Writer, t *mast.Title) {
 	// rfc tag
 	attrs := Attributes(
 		[]string{"version", "ipr", "submissionType", "category", "xml:lang", "consensus", "xmlns:xi"},
-		[]string{"3", d.Ipr, "IETF", StatusToCategory[d.SeriesInfo.Status], "en", fmt.Sprintf("%t", d.Consensus), "http://www.w3.org/2001/XInclude"},
+		[]string{"3", d.Ipr, "IETF", StatusToCategory[d.SeriesInfo.Status], r.opts.Language.Tag, fmt.Sprintf("%t", d.Consensus), "http://www.w3.org/2001/XInclude"},
 	)
 	attrs = append(attrs, Attributes(
 		[]string{"updates", "obsoletes"},
@@ -134,22 +133,23 @@ func (r *Renderer) TitleAuthor(w io.Writer, a mast.Author) {
 	r.cr(w)
 }
 
-// TitleDate outputs the date from the TOML title block.
-func (r *Renderer) TitleDate(w io.Writer, d time.Time) {
+// TitleDate outputs the date from the TOML title block. Only the components of the date
+// that are specified are output.
+func (r *Renderer) TitleDate(w io.Writer, d mast.Date) {
 	if d.IsZero() { // not specified
 		r.outs(w, "<date/>\n")
 		return
 	}
 
 	var attr = []string{}
-	if x := d.Year(); x > 0 {
-		attr = append(attr, fmt.Sprintf(`year="%d"`, x))
+	if d.Year > 0 {
+		attr = append(attr, fmt.Sprintf(`year="%d"`, d.Year))
 	}
-	if d.Month() > 0 {
-		attr = append(attr, d.Format("month=\"January\""))
+	if d.Month > 0 {
+		attr = append(attr, fmt.Sprintf(`month="%s"`, d.Month))
 	}
-	if x := d.Day(); x > 0 {
-		attr = append(attr, fmt.Sprintf(`day="%d"`, x))
+	if d.Day > 0 {
+		attr = append(attr, fmt.Sprintf(`day="%d"`, d.Day))
 	}
 	r.outTag(w, "<date", attr)
 	r.outs(w, "</date>\n")
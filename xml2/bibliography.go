@@ -24,9 +24,9 @@ func (r *Renderer) bibliography(w io.Writer, node *mast.Bibliography, entering b
 
 	switch node.Type {
 	case ast.CitationTypeInformative:
-		r.outs(w, `<references title="Informative References">`)
+		r.outs(w, `<references title="`+xml.EscapeHTMLString(r.opts.Language.InformativeReferences)+`">`)
 	case ast.CitationTypeNormative:
-		r.outs(w, `<references title="Normative References">`)
+		r.outs(w, `<references title="`+xml.EscapeHTMLString(r.opts.Language.NormativeReferences)+`">`)
 	}
 	r.cr(w)
 }
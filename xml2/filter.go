@@ -0,0 +1,136 @@
+package xml2
+
+import (
+	"strings"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/mmarkdown/mmark/mast"
+	"github.com/mmarkdown/mmark/xml"
+)
+
+// Elements maps each RFC 7749 element to the attributes allowed on it, this is derived from
+// schema/rfc7749.rng. The xml:space attributes are left out.
+var Elements = map[string][]string{
+	"abstract":     nil,
+	"address":      nil,
+	"annotation":   nil,
+	"area":         nil,
+	"artwork":      {"align", "alt", "height", "name", "src", "type", "width"},
+	"author":       {"fullname", "initials", "role", "surname"},
+	"back":         nil,
+	"c":            nil,
+	"city":         nil,
+	"code":         nil,
+	"country":      nil,
+	"cref":         {"anchor", "source"},
+	"date":         {"day", "month", "year"},
+	"email":        nil,
+	"eref":         {"target"},
+	"facsimile":    nil,
+	"figure":       {"align", "alt", "anchor", "height", "src", "suppress-title", "title", "width"},
+	"format":       {"octets", "target", "type"},
+	"front":        nil,
+	"iref":         {"item", "primary", "subitem"},
+	"keyword":      nil,
+	"list":         {"counter", "hangIndent", "style"},
+	"middle":       nil,
+	"note":         {"title"},
+	"organization": {"abbrev"},
+	"phone":        nil,
+	"postal":       nil,
+	"postamble":    nil,
+	"preamble":     nil,
+	"reference":    {"anchor", "target"},
+	"references":   {"title"},
+	"region":       nil,
+	"rfc":          {"category", "consensus", "docName", "ipr", "iprExtract", "number", "obsoletes", "seriesNo", "submissionType", "updates"},
+	"section":      {"anchor", "title", "toc"},
+	"seriesInfo":   {"name", "value"},
+	"spanx":        {"style"},
+	"street":       nil,
+	"t":            {"anchor", "hangText"},
+	"texttable":    {"align", "anchor", "style", "suppress-title", "title"},
+	"title":        {"abbrev"},
+	"ttcol":        {"align", "width"},
+	"uri":          nil,
+	"vspace":       {"blankLines"},
+	"workgroup":    nil,
+	"xref":         {"format", "pageno", "target"},
+}
+
+var filterFunc mast.FilterFunc = func(s string) bool {
+	switch s {
+	case "id": // will translate to anchor so OK.
+		return true
+	case "class": // there are no classes
+		return false
+	case "empty": // newer attributes from RFC 7991
+		return false
+	}
+
+	// l33t data- HTML5 attributes
+	if strings.HasPrefix(s, "data-") {
+		return false
+	}
+
+	return true
+}
+
+// AttributeFilter is the default attribute filter for the XML2 renderer. It drops class, empty
+// and data- attributes and any attribute that is not allowed on element according to Elements.
+func AttributeFilter(element, key string) bool {
+	if !filterFunc(key) {
+		return false
+	}
+	return xml.Allowed(Elements, element, key)
+}
+
+// nodeElements returns the XML element(s) node will be rendered as. It returns nil when node
+// isn't rendered as an element that can carry block level attributes.
+func nodeElements(node ast.Node) []string {
+	switch node := node.(type) {
+	case *ast.Heading:
+		if node.IsSpecial {
+			if xml.IsAbstract(node.Literal) {
+				return []string{"abstract"}
+			}
+			return []string{"note"}
+		}
+		return []string{"section"}
+	case *ast.Paragraph:
+		return []string{"t"}
+	case *ast.List, *ast.BlockQuote:
+		return []string{"list"}
+	case *ast.CodeBlock:
+		if _, inFigure := node.Parent.(*ast.CaptionFigure); inFigure {
+			return []string{"artwork"}
+		}
+		return []string{"figure", "artwork"}
+	case *ast.MathBlock:
+		return []string{"figure", "artwork"}
+	case *ast.Image:
+		return []string{"artwork"}
+	case *ast.CaptionFigure:
+		return []string{"figure"}
+	case *ast.Table:
+		return []string{"texttable"}
+	case *ast.TableCell:
+		if node.IsHeader {
+			return []string{"ttcol"}
+		}
+		return []string{"c"}
+	}
+	return nil
+}
+
+// attributeFilter returns a mast.FilterFunc that checks the attributes of node with the attribute filter
+// from the options. Attributes that are dropped, but aren't HTML attributes, are logged. The markers
+// attribute of a code block is kept, RFC 7749 has no such attribute, but codeBlock writes the markers
+// in the text and leaves the attribute out.
+func (r *Renderer) attributeFilter(node ast.Node) mast.FilterFunc {
+	filter := xml.ElementFilter(nodeElements(node), r.opts.AttributeFilter, filterFunc)
+	if _, ok := node.(*ast.CodeBlock); !ok {
+		return filter
+	}
+	return func(key string) bool { return key == "markers" || filter(key) }
+}
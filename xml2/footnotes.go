@@ -0,0 +1,86 @@
+package xml2
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/mmarkdown/mmark/mast"
+	"github.com/mmarkdown/mmark/xml"
+)
+
+func (r *Renderer) footnoteLink(w io.Writer, link *ast.Link) {
+	switch r.opts.Footnotes {
+	case xml.FootnoteCref:
+		// A cref can only contain text in RFC 7749.
+		buf := &bytes.Buffer{}
+		xml.FootnoteWalk(buf, xml.FootnoteContent(link), func(node ast.Node, entering bool) ast.WalkStatus {
+			if entering {
+				if leaf := node.AsLeaf(); leaf != nil {
+					buf.Write(leaf.Literal)
+				}
+			}
+			return ast.GoToNext
+		})
+		r.outs(w, "<cref>")
+		html.EscapeHTML(w, buf.Bytes())
+		r.outs(w, "</cref>")
+	case xml.FootnoteInline:
+		r.outs(w, xml.FootnoteOpen(link))
+		xml.FootnoteWalk(w, xml.FootnoteContent(link), func(node ast.Node, entering bool) ast.WalkStatus {
+			return r.RenderNode(w, node, entering)
+		})
+		r.outs(w, ")")
+	case xml.FootnoteNotes:
+		if r.footnoteSections == nil {
+			r.footnoteSections = map[int]string{}
+		}
+		if _, ok := r.footnoteSections[link.NoteID]; !ok && r.section != nil {
+			if id := mast.Attribute(r.section, "id"); id != nil {
+				r.footnoteSections[link.NoteID] = string(id)
+			}
+		}
+		n := strconv.Itoa(link.NoteID)
+		r.outs(w, `<xref target="`+xml.FootnoteID(link.NoteID)+`">[`+n+`]</xref>`)
+	}
+}
+
+// footnotes opens the Footnotes section, it is closed as any other section.
+func (r *Renderer) footnotes(w io.Writer, node *ast.Footnotes) {
+	if r.opts.Footnotes != xml.FootnoteNotes {
+		return
+	}
+	r.sectionClose(w, &ast.Heading{Level: 1})
+	r.cr(w)
+	r.outs(w, `<section anchor="`+r.ensureUniqueHeadingID("footnotes")+`" title="`)
+	html.EscapeHTML(w, []byte(r.opts.Language.Footnotes))
+	r.outs(w, `">`)
+	r.cr(w)
+}
+
+// footnoteParagraphEnter opens the paragraphs of a footnote, the first one gets the anchor and number
+// of the footnote.
+func (r *Renderer) footnoteParagraphEnter(w io.Writer, item *ast.ListItem, para *ast.Paragraph) {
+	if ast.GetFirstChild(item) != para {
+		r.outs(w, "<t>")
+		return
+	}
+	n := xml.FootnoteNumber(item)
+	r.outs(w, `<t anchor="`+xml.FootnoteID(n)+`">[`+strconv.Itoa(n)+"] ")
+}
+
+// footnoteParagraphExit closes the paragraphs of a footnote, the last one links back to the section
+// the footnote is referenced from.
+func (r *Renderer) footnoteParagraphExit(w io.Writer, item *ast.ListItem, para *ast.Paragraph) {
+	if ast.GetNextNode(para) == nil {
+		if id, ok := r.footnoteSections[xml.FootnoteNumber(item)]; ok {
+			r.outs(w, ` (<xref target="`)
+			html.EscapeHTML(w, []byte(id))
+			r.outs(w, `"/>)`)
+		}
+	}
+	r.outs(w, "</t>")
+	r.cr(w)
+}
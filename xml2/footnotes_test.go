@@ -0,0 +1,52 @@
+package xml2
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/xml"
+)
+
+func TestFootnotes(t *testing.T) {
+	in := "# One\n\nText[^a] and ^[inline *note*].\n\n# Two\n\nAgain[^a].\n\n[^a]: A note.\n"
+	tests := []struct {
+		style xml.FootnoteStyle
+		want  []string
+	}{
+		{xml.FootnoteNone, []string{
+			`<t>Text and .</t>`,
+			`<t>Again.</t>`,
+		}},
+		{xml.FootnoteNotes, []string{
+			`<t>Text<xref target="fn-1">[1]</xref> and <xref target="fn-2">[2]</xref>.</t>`,
+			`<section anchor="footnotes" title="Footnotes">`,
+			`<t anchor="fn-1">[1] A note. (<xref target="one"/>)</t>`,
+			`<t anchor="fn-2">[2] inline <spanx style="emph">note</spanx> (<xref target="one"/>)</t>`,
+		}},
+		{xml.FootnoteCref, []string{
+			`<t>Text<cref>A note.</cref> and <cref>inline note</cref>.</t>`,
+			`<t>Again<cref>A note.</cref>.</t>`,
+		}},
+		{xml.FootnoteInline, []string{
+			`<t>Text (A note.) and (inline <spanx style="emph">note</spanx>).</t>`,
+			`<t>Again (A note.).</t>`,
+		}},
+	}
+	for _, tc := range tests {
+		p := parser.NewWithExtensions(parser.CommonExtensions | parser.AutoHeadingIDs | parser.Footnotes)
+		doc := markdown.Parse([]byte(in), p)
+		xml.FootnoteParagraphs(doc)
+		r := NewRenderer(RendererOptions{Flags: XMLFragment, Footnotes: tc.style})
+		got := string(markdown.Render(doc, r))
+		for _, want := range tc.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("want %q in output, got %s, for %d", want, got, tc.style)
+			}
+		}
+		if tc.style != xml.FootnoteNotes && strings.Contains(got, "ootnote") {
+			t.Errorf("want no footnotes section, got %s, for %d", got, tc.style)
+		}
+	}
+}
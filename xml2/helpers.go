@@ -2,7 +2,6 @@ package xml2
 
 import (
 	"bytes"
-	"fmt"
 	"io"
 	"strings"
 
@@ -86,22 +85,7 @@ func (r *Renderer) sectionClose(w io.Writer, new *ast.Heading) {
 }
 
 func (r *Renderer) ensureUniqueHeadingID(id string) string {
-	for count, found := r.headingIDs[id]; found; count, found = r.headingIDs[id] {
-		tmp := fmt.Sprintf("%s-%d", id, count+1)
-
-		if _, tmpFound := r.headingIDs[tmp]; !tmpFound {
-			r.headingIDs[id] = count + 1
-			id = tmp
-		} else {
-			id = id + "-1"
-		}
-	}
-
-	if _, found := r.headingIDs[id]; !found {
-		r.headingIDs[id] = 0
-	}
-
-	return id
+	return mast.UniqueID(r.headingIDs, id)
 }
 
 func appendLanguageAttr(node ast.Node, info []byte) {
@@ -4,10 +4,13 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"strconv"
 	"strings"
 
 	"github.com/gomarkdown/markdown/ast"
 	"github.com/gomarkdown/markdown/html"
+	"github.com/mmarkdown/mmark/lang"
+	"github.com/mmarkdown/mmark/latex"
 	"github.com/mmarkdown/mmark/mast"
 	"github.com/mmarkdown/mmark/xml"
 )
@@ -43,6 +46,25 @@ type RendererOptions struct {
 
 	// Generator is a comment that is inserted in the generated XML to show what rendered it.
 	Generator string
+
+	// AttributeFilter decides which block level attributes are allowed on an element. If nil,
+	// AttributeFilter is used.
+	AttributeFilter mast.ElementFilterFunc
+
+	// Renderers holds the functions that render specific node types, these are consulted
+	// before RenderNodeHook.
+	Renderers mast.Renderers
+
+	// UnknownNode tells what to do with node types the renderer doesn't know.
+	UnknownNode mast.UnknownNode
+
+	// Language holds the labels for the generated headings, its Tag is used for xml:lang. If Tag is
+	// empty, English is used.
+	Language lang.Lang
+
+	// Footnotes tells how footnotes are rendered, FootnoteNone (the default) discards them. For the
+	// other styles the parser must not skip the footnote list.
+	Footnotes xml.FootnoteStyle
 }
 
 // Renderer implements Renderer interface for IETF XMLv2 output. See RFC 7941.
@@ -52,28 +74,13 @@ type Renderer struct {
 	documentMatter ast.DocumentMatters // keep track of front/main/back matter
 	section        *ast.Heading        // current open section
 	title          bool                // did we output a title block
-	filter         mast.FilterFunc     // filter for attributes.
 
 	// Track heading IDs to prevent ID collision in a single generation.
 	headingIDs map[string]int
-}
 
-var filterFunc mast.FilterFunc = func(s string) bool {
-	switch s {
-	case "id": // will translate to anchor so OK.
-		return true
-	case "class": // there are no classes
-		return false
-	case "empty": // newer attributes from RFC 7991
-		return false
-	}
-
-	// l33t data- HTML5 attributes
-	if strings.HasPrefix(s, "data-") {
-		return false
-	}
+	footnoteSections map[int]string // footnote number -> anchor of the section it is first referenced in
 
-	return true
+	err error // set when rendering is stopped because of an unknown node
 }
 
 // NewRenderer creates and configures an Renderer object, which satisfies the Renderer interface.
@@ -82,7 +89,13 @@ func NewRenderer(opts RendererOptions) *Renderer {
 	if opts.Generator == "" {
 		opts.Generator = xml.Generator
 	}
-	return &Renderer{opts: opts, headingIDs: make(map[string]int), filter: filterFunc}
+	if opts.AttributeFilter == nil {
+		opts.AttributeFilter = AttributeFilter
+	}
+	if opts.Language.Tag == "" {
+		opts.Language = lang.New("en")
+	}
+	return &Renderer{opts: opts, headingIDs: make(map[string]int)}
 }
 
 func (r *Renderer) text(w io.Writer, text *ast.Text) {
@@ -109,7 +122,7 @@ func (r *Renderer) strong(w io.Writer, node *ast.Strong, entering bool) {
 	// *iff* we have a text node as a child *and* that text is 2119, we output bcp14 tags, otherwise just string.
 	text := ast.GetFirstChild(node)
 	if t, ok := text.(*ast.Text); ok {
-		if xml.Is2119(t.Literal) {
+		if mast.Is2119(t.Literal) {
 			// out as-is.
 			r.outOneOf(w, entering, "", "")
 			return
@@ -221,12 +234,37 @@ func (r *Renderer) citation(w io.Writer, node *ast.Citation, entering bool) {
 			continue
 		}
 
+		// RFC 7749 has no section attribute on xref, the section is put in the text around it.
+		loc := mast.CitationLocator(node.Suffix[i])
+		if loc == nil {
+			r.outTag(w, "<xref", []string{fmt.Sprintf(`target="%s"`, c)})
+			r.outs(w, "</xref>")
+			continue
+		}
+		section := loc.Text(r.opts.Language.Section, r.opts.Language.Appendix)
+		switch loc.Format {
+		case "of":
+			r.outs(w, section+" "+r.opts.Language.Of+" ")
+		case "bare":
+			r.outs(w, section)
+			continue
+		}
 		r.outTag(w, "<xref", []string{fmt.Sprintf(`target="%s"`, c)})
 		r.outs(w, "</xref>")
+		switch loc.Format {
+		case "comma":
+			r.outs(w, ", "+section)
+		case "parens":
+			r.outs(w, " ("+section+")")
+		}
 	}
 }
 
 func (r *Renderer) paragraphEnter(w io.Writer, para *ast.Paragraph) {
+	if p, ok := para.Parent.(*ast.ListItem); ok && p.RefLink != nil {
+		r.footnoteParagraphEnter(w, p, para)
+		return
+	}
 	// Skip outputting </t> in lists and in caption figures.
 	if p, ok := para.Parent.(*ast.ListItem); ok {
 		// Fake multiple paragraphs by inserting a hard break.
@@ -247,6 +285,10 @@ func (r *Renderer) paragraphEnter(w io.Writer, para *ast.Paragraph) {
 }
 
 func (r *Renderer) paragraphExit(w io.Writer, para *ast.Paragraph) {
+	if p, ok := para.Parent.(*ast.ListItem); ok && p.RefLink != nil {
+		r.footnoteParagraphExit(w, p, para)
+		return
+	}
 	// Skip outputting </t> in lists and in caption figures.
 	if _, ok := para.Parent.(*ast.ListItem); ok {
 		return
@@ -268,9 +310,6 @@ func (r *Renderer) paragraph(w io.Writer, para *ast.Paragraph, entering bool) {
 }
 
 func (r *Renderer) listEnter(w io.Writer, nodeData *ast.List) {
-	if nodeData.IsFootnotesList {
-		return
-	}
 	r.cr(w)
 
 	openTag := "<list"
@@ -297,9 +336,6 @@ func (r *Renderer) listEnter(w io.Writer, nodeData *ast.List) {
 }
 
 func (r *Renderer) listExit(w io.Writer, list *ast.List) {
-	if list.IsFootnotesList {
-		return
-	}
 	closeTag := "</list>"
 	if list.ListFlags&ast.ListTypeOrdered != 0 {
 		//closeTag = "</ol>"
@@ -337,10 +373,6 @@ func (r *Renderer) list(w io.Writer, list *ast.List, entering bool) {
 }
 
 func (r *Renderer) listItemEnter(w io.Writer, listItem *ast.ListItem) {
-	if listItem.RefLink != nil { // footnotes
-		return
-	}
-
 	openTag := "<t>"
 	if listItem.ListFlags&ast.ListTypeDefinition != 0 {
 		openTag = "<vspace />"
@@ -352,10 +384,6 @@ func (r *Renderer) listItemEnter(w io.Writer, listItem *ast.ListItem) {
 }
 
 func (r *Renderer) listItemExit(w io.Writer, listItem *ast.ListItem) {
-	if listItem.RefLink != nil {
-		return
-	}
-
 	closeTag := "</t>"
 	if listItem.ListFlags&ast.ListTypeTerm != 0 {
 		closeTag = `">`
@@ -372,32 +400,49 @@ func (r *Renderer) listItem(w io.Writer, listItem *ast.ListItem, entering bool)
 	}
 }
 
+// codeBlock renders the code block, with markers="true" the code is put between the "<CODE BEGINS>"
+// and "<CODE ENDS>" lines, the file name is taken from the name attribute.
 func (r *Renderer) codeBlock(w io.Writer, codeBlock *ast.CodeBlock) {
+	markers := string(mast.Attribute(codeBlock, "markers")) == "true"
 	mast.AttributeInit(codeBlock)
 	appendLanguageAttr(codeBlock, codeBlock.Info)
 
 	r.cr(w)
-	_, inFigure := codeBlock.Parent.(*ast.CaptionFigure)
+	parent := codeBlock.Parent
+	if _, ok := parent.(*mast.ArtSet); ok {
+		parent = parent.GetParent()
+	}
+	_, inFigure := parent.(*ast.CaptionFigure)
 	if inFigure {
 		// Drop anchor for now, but need to figure out what to allow here.
 		mast.DeleteAttribute(codeBlock, "id")
-		r.outTag(w, "<artwork", html.BlockAttrs(codeBlock))
+		r.outTag(w, "<artwork", xml.ElementAttrs("artwork", html.BlockAttrs(codeBlock), r.opts.AttributeFilter))
 	} else {
 		typ := mast.Attribute(codeBlock, "type") // only valid on artwork
 		mast.DeleteAttribute(codeBlock, "type")
-		r.outTag(w, "<figure", html.BlockAttrs(codeBlock))
+		r.outTag(w, "<figure", xml.ElementAttrs("figure", html.BlockAttrs(codeBlock), r.opts.AttributeFilter))
 		mast.DeleteAttribute(codeBlock, "id")
 		if typ != nil {
 			mast.SetAttribute(codeBlock, "type", typ)
 		}
-		r.outTag(w, "<artwork", html.BlockAttrs(codeBlock))
+		r.outTag(w, "<artwork", xml.ElementAttrs("artwork", html.BlockAttrs(codeBlock), r.opts.AttributeFilter))
 	}
 
+	if markers {
+		begin := "<CODE BEGINS>"
+		if name := mast.Attribute(codeBlock, "name"); name != nil {
+			begin += ` file "` + string(name) + `"`
+		}
+		html.EscapeHTML(w, []byte(begin+"\n"))
+	}
 	if r.opts.Comments != nil {
 		xml.EscapeHTMLCallouts(w, codeBlock.Literal, r.opts.Comments)
 	} else {
 		html.EscapeHTML(w, codeBlock.Literal)
 	}
+	if markers {
+		r.outs(w, "&lt;CODE ENDS&gt;\n")
+	}
 	if inFigure {
 		r.outs(w, "</artwork>")
 	} else {
@@ -406,6 +451,80 @@ func (r *Renderer) codeBlock(w io.Writer, codeBlock *ast.CodeBlock) {
 	r.cr(w)
 }
 
+// raw outputs the raw content if it is for RFC 7749 XML.
+func (r *Renderer) raw(w io.Writer, raw *mast.Raw) {
+	if raw.Format != "xml2" {
+		return
+	}
+	if raw.Block {
+		r.cr(w)
+	}
+	r.out(w, raw.Literal)
+	if raw.Block {
+		r.cr(w)
+	}
+}
+
+// tableUnspan replaces the cell spans in tab with empty cells, as RFC 7749 tables can't span cells.
+func tableUnspan(tab *ast.Table) {
+	spans := false
+	for _, section := range tab.GetChildren() {
+		covered := map[int]int{} // column -> number of rows it is still covered by a rowspan
+		for _, row := range section.GetChildren() {
+			next := map[int]int{}
+			cells := []ast.Node{}
+			col := 0
+			header := false
+			empty := func() {
+				cell := &ast.TableCell{IsHeader: header}
+				cell.SetParent(row)
+				cells = append(cells, cell)
+			}
+			fill := func() {
+				for ; covered[col] > 0; col++ {
+					next[col] = covered[col] - 1
+					empty()
+				}
+			}
+			for _, child := range row.GetChildren() {
+				cell, ok := child.(*ast.TableCell)
+				if !ok {
+					cells = append(cells, child)
+					continue
+				}
+				header = cell.IsHeader
+				fill()
+				cells = append(cells, cell)
+				rows, _ := strconv.Atoi(string(mast.Attribute(cell, "rowspan")))
+				mast.DeleteAttribute(cell, "rowspan")
+				span := cell.ColSpan
+				cell.ColSpan = 0
+				if span < 1 {
+					span = 1
+				}
+				if rows > 1 || span > 1 {
+					spans = true
+				}
+				for i := 0; i < span; i++ {
+					if rows > 1 {
+						next[col+i] = rows - 1
+					}
+					if i > 0 {
+						empty()
+					}
+				}
+				col += span
+			}
+			fill()
+			row.SetChildren(cells)
+			covered = next
+		}
+	}
+	if spans {
+		log.Printf("Table cell spans are not supported in RFC 7749, the spanned cells are left empty")
+	}
+}
+
 func (r *Renderer) tableCell(w io.Writer, tableCell *ast.TableCell, entering bool) {
 	if !entering {
 		r.outOneOf(w, tableCell.IsHeader, "</ttcol>", "</c>")
@@ -419,8 +538,9 @@ func (r *Renderer) tableCell(w io.Writer, tableCell *ast.TableCell, entering boo
 	if tableCell.IsHeader {
 		openTag = "<ttcol"
 	}
+	// RFC 7749 only has the align attribute on <ttcol>, it's the alignment of the whole column.
 	align := tableCell.Align.String()
-	if align != "" {
+	if align != "" && tableCell.IsHeader {
 		mast.SetAttribute(tableCell, "align", []byte(align))
 	}
 	if ast.GetPrevNode(tableCell) == nil {
@@ -445,6 +565,22 @@ func (r *Renderer) callout(w io.Writer, callout *ast.Callout) {
 	r.outs(w, "</spanx>")
 }
 
+func (r *Renderer) comment(w io.Writer, comment *mast.Comment) {
+	attr := []string{}
+	if len(comment.Source) > 0 {
+		attr = append(attr, `source="`+xml.EscapeHTMLString(string(comment.Source))+`"`)
+	}
+	r.outTag(w, "<cref", attr)
+	// A cref can only contain text in RFC 7749.
+	ast.WalkFunc(comment, func(node ast.Node, entering bool) ast.WalkStatus {
+		if leaf := node.AsLeaf(); leaf != nil && entering {
+			html.EscapeHTML(w, leaf.Literal)
+		}
+		return ast.GoToNext
+	})
+	r.outs(w, "</cref>")
+}
+
 func (r *Renderer) crossReference(w io.Writer, cr *ast.CrossReference, entering bool) {
 	if isHangText(cr) {
 		if entering {
@@ -478,6 +614,9 @@ func (r *Renderer) index(w io.Writer, index *ast.Index) {
 
 func (r *Renderer) link(w io.Writer, link *ast.Link, entering bool) {
 	if link.Footnote != nil {
+		if entering {
+			r.footnoteLink(w, link)
+		}
 		return
 	}
 
@@ -535,11 +674,31 @@ func (r *Renderer) code(w io.Writer, node *ast.Code) {
 	r.outs(w, "</spanx>")
 }
 
-func (r *Renderer) mathBlock(w io.Writer, mathBlock *ast.MathBlock) {
+func (r *Renderer) math(w io.Writer, math *ast.Math) {
+	r.outs(w, `<spanx style="verb">`)
+	text, err := latex.Text(string(math.Literal), true)
+	if err != nil {
+		log.Printf("Failed to convert math %q: %s, using it as is", math.Literal, err)
+		text = string(math.Literal)
+	}
+	html.EscapeHTML(w, []byte(text))
+	r.outs(w, "</spanx>")
+}
+
+func (r *Renderer) mathBlock(w io.Writer, mathBlock *ast.MathBlock, entering bool) {
+	if !entering {
+		return
+	}
 	r.outs(w, `<figure><artwork type="math">`+"\n")
-	if r.opts.Comments != nil {
+	art, err := latex.Art(string(mathBlock.Literal), true)
+	switch {
+	case err == nil:
+		html.EscapeHTML(w, []byte(art+"\n"))
+	case r.opts.Comments != nil:
+		log.Printf("Failed to convert math block: %s, using it as is", err)
 		xml.EscapeHTMLCallouts(w, mathBlock.Literal, r.opts.Comments)
-	} else {
+	default:
+		log.Printf("Failed to convert math block: %s, using it as is", err)
 		html.EscapeHTML(w, mathBlock.Literal)
 	}
 	r.outs(w, `</artwork></figure>`+"\n")
@@ -587,6 +746,7 @@ func (r *Renderer) table(w io.Writer, tab *ast.Table, entering bool) {
 		return
 	}
 
+	tableUnspan(tab)
 	r.outs(w, "<texttable")
 	r.outAttr(w, html.BlockAttrs(tab))
 	// Now render the caption if our parent is a ast.CaptionFigure
@@ -650,8 +810,11 @@ func (r *Renderer) blockQuote(w io.Writer, block *ast.BlockQuote, entering bool)
 
 // RenderNode renders a markdown node to XML.
 func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.WalkStatus {
+	mast.AttributeFilter(node, r.attributeFilter(node))
 
-	mast.AttributeFilter(node, r.filter)
+	if fn := r.opts.Renderers.Lookup(node); fn != nil {
+		return fn(w, node, entering)
+	}
 
 	if r.opts.RenderNodeHook != nil {
 		status, didHandle := r.opts.RenderNodeHook(w, node, entering)
@@ -672,6 +835,8 @@ func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.Wal
 		r.bibliographyItem(w, node)
 	case *mast.DocumentIndex, *mast.IndexLetter, *mast.IndexItem, *mast.IndexSubItem, *mast.IndexLink:
 		// generated by xml2rfc, do nothing.
+	case *mast.TableOfContents:
+		// generated by xml2rfc, do nothing.
 	case *ast.Text:
 		r.text(w, node)
 	case *ast.Softbreak:
@@ -713,12 +878,28 @@ func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.Wal
 		r.htmlSpan(w, node) // only html comments are allowed.
 	case *ast.HTMLBlock:
 		// discard; we use these only for <references>.
+	case *ast.Footnotes:
+		if entering {
+			r.footnotes(w, node)
+		}
 	case *ast.List:
+		if node.IsFootnotesList {
+			if r.opts.Footnotes != xml.FootnoteNotes {
+				return ast.SkipChildren
+			}
+			break
+		}
 		r.list(w, node, entering)
 	case *ast.ListItem:
+		if node.RefLink != nil {
+			break
+		}
 		r.listItem(w, node, entering)
 	case *ast.CodeBlock:
 		r.codeBlock(w, node)
+	case *mast.ArtSet:
+		// RFC 7749 has no SVG, only the ascii-art is rendered.
+		return ast.GoToNext
 	case *ast.Caption:
 		// no tags because we are used in attributes, i.e. title=
 		// See comment in xml/renderer.go. The same is true here, *but*, because we don't
@@ -745,6 +926,13 @@ func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.Wal
 		r.blockQuote(w, node, entering)
 	case *ast.Aside:
 		// ignore and text render the child text as-is.
+	case *mast.Comment:
+		if entering {
+			r.comment(w, node)
+		}
+		return ast.SkipChildren
+	case *mast.Raw:
+		r.raw(w, node)
 	case *ast.CrossReference:
 		r.crossReference(w, node, entering)
 	case *ast.Index:
@@ -754,9 +942,7 @@ func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.Wal
 	case *ast.Link:
 		r.link(w, node, entering)
 	case *ast.Math:
-		r.outOneOf(w, true, `<spanx style="verb">`, "</spanx>")
-		html.EscapeHTML(w, node.Literal)
-		r.outOneOf(w, false, `<spanx style="verb">`, "</spanx>")
+		r.math(w, node)
 	case *ast.Image:
 		if r.opts.Flags&SkipImages != 0 {
 			return ast.SkipChildren
@@ -765,7 +951,7 @@ func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.Wal
 	case *ast.Code:
 		r.code(w, node)
 	case *ast.MathBlock:
-		r.mathBlock(w, node)
+		r.mathBlock(w, node, entering)
 	case *ast.Subscript:
 		r.outOneOf(w, true, "_(", ")")
 		if entering {
@@ -779,11 +965,26 @@ func (r *Renderer) RenderNode(w io.Writer, node ast.Node, entering bool) ast.Wal
 		}
 		r.outOneOf(w, false, "^(", ")")
 	default:
-		panic(fmt.Sprintf("Unknown node %T", node))
+		status, err := r.opts.UnknownNode.Unknown(node, entering)
+		if err != nil {
+			r.err = err
+		}
+		return status
 	}
 	return ast.GoToNext
 }
 
+// Register registers fn as the function that renders nodes of the same type as node.
+func (r *Renderer) Register(node ast.Node, fn mast.RenderFunc) {
+	if r.opts.Renderers == nil {
+		r.opts.Renderers = mast.Renderers{}
+	}
+	r.opts.Renderers.Register(node, fn)
+}
+
+// Err returns the error that stopped the rendering, if any.
+func (r *Renderer) Err() error { return r.err }
+
 // RenderHeader writes HTML document preamble and TOC if requested.
 func (r *Renderer) RenderHeader(w io.Writer, ast ast.Node) {
 	if r.opts.Flags&XMLFragment != 0 {
@@ -0,0 +1,83 @@
+package xml2
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/mmarkdown/mmark/lang"
+	"github.com/mmarkdown/mmark/mast"
+)
+
+func TestCodeMarkers(t *testing.T) {
+	in := "{name=\"hello.c\" markers=\"true\"}\n``` c\nint x;\n```\n"
+	p := parser.NewWithExtensions(parser.CommonExtensions | parser.Attributes)
+	doc := markdown.Parse([]byte(in), p)
+	buf := &bytes.Buffer{}
+	log.SetOutput(buf)
+	defer log.SetOutput(os.Stderr)
+	got := string(markdown.Render(doc, NewRenderer(RendererOptions{Flags: XMLFragment})))
+
+	want := `<artwork name="hello.c" type="c">&lt;CODE BEGINS&gt; file &quot;hello.c&quot;` + "\nint x;\n&lt;CODE ENDS&gt;\n</artwork>"
+	if !strings.Contains(got, want) {
+		t.Errorf("want %q in output, got %s", want, got)
+	}
+	if strings.Contains(got, "markers") {
+		t.Errorf("want no markers attribute, got %s", got)
+	}
+	if buf.Len() > 0 {
+		t.Errorf("want no warnings, got %s", buf)
+	}
+	if markers := mast.Attribute(ast.GetFirstChild(doc), "markers"); string(markers) != "true" {
+		t.Errorf("want markers attribute left in the AST, got %q", markers)
+	}
+}
+
+func TestCodeBlockAttributes(t *testing.T) {
+	in := "{#code name=\"x.c\"}\n``` c\nint x;\n```\n"
+	p := parser.NewWithExtensions(parser.CommonExtensions | parser.Attributes)
+	doc := markdown.Parse([]byte(in), p)
+	got := string(markdown.Render(doc, NewRenderer(RendererOptions{Flags: XMLFragment})))
+
+	// RFC 7749 has no name on <figure> and no anchor on <artwork>.
+	want := `<figure anchor="code"><artwork name="x.c" type="c">`
+	if !strings.Contains(got, want) {
+		t.Errorf("want %q in output, got %s", want, got)
+	}
+}
+
+func TestTableCellAlign(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log.SetOutput(buf)
+	defer log.SetOutput(os.Stderr)
+
+	in := "A | B\n:--|--:\na | b\n"
+	p := parser.NewWithExtensions(parser.CommonExtensions)
+	doc := markdown.Parse([]byte(in), p)
+	got := string(markdown.Render(doc, NewRenderer(RendererOptions{Flags: XMLFragment})))
+
+	for _, want := range []string{`<ttcol align="left">A</ttcol>`, `<ttcol align="right">B</ttcol>`, "<c>a</c>", "<c>b</c>"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want %q in output, got %s", want, got)
+		}
+	}
+	if buf.Len() > 0 {
+		t.Errorf("want no warnings, got %s", buf)
+	}
+}
+
+func TestCitationLocatorLang(t *testing.T) {
+	in := "See [@RFC8446, section 4.2].\n"
+	p := parser.NewWithExtensions(parser.CommonExtensions | parser.Mmark)
+	doc := markdown.Parse([]byte(in), p)
+	got := string(markdown.Render(doc, NewRenderer(RendererOptions{Flags: XMLFragment, Language: lang.New("de")})))
+
+	if want := `Abschnitt 4.2 von <xref target="RFC8446">`; !strings.Contains(got, want) {
+		t.Errorf("want %q in output, got %s", want, got)
+	}
+}
@@ -23,7 +23,7 @@ func (r *Renderer) titleBlock(w io.Writer, t *mast.Title) {
 
 	attrs := xml.Attributes(
 		[]string{"ipr", "submissionType", "category", "xml:lang", "consensus"},
-		[]string{d.Ipr, d.SeriesInfo.Stream, xml.StatusToCategory[d.SeriesInfo.Status], "en", consensusToTerm[d.Consensus]},
+		[]string{d.Ipr, d.SeriesInfo.Stream, xml.StatusToCategory[d.SeriesInfo.Status], r.opts.Language.Tag, consensusToTerm[d.Consensus]},
 	)
 	attrs = append(attrs, xml.Attributes(
 		[]string{"updates", "obsoletes"},